@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+// checkWritable 非 Unix 系统没有不产生副作用的检查方式，交给 New 打开文件时报告
+func checkWritable(dir string) error {
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package logger
+
+import "syscall"
+
+// checkWritable 检查当前进程能否在目录中创建文件（需要写和执行权限）
+func checkWritable(dir string) error {
+	const wOK, xOK = 0x2, 0x1
+	return syscall.Access(dir, wOK|xOK)
+}
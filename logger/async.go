@@ -0,0 +1,335 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// asyncEntry 异步队列中的一条日志
+type asyncEntry struct {
+	core   zapcore.Core // 写入目标（包含 With 添加的字段）
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+// asyncPipeline 每个日志器独占的异步写入管道，单个写入协程按入队顺序写入，保证同一日志器的日志有序
+type asyncPipeline struct {
+	queue chan asyncEntry
+	done  chan struct{}
+	root  zapcore.Core // 用于写入丢弃统计
+
+	policy    OverflowPolicy
+	keepLevel zapcore.Level // OverflowDropBelowLevel 时保留的最低级别
+	spill     *spillBuffer  // 仅 OverflowSpill 时使用
+
+	mu       sync.RWMutex // 入队时持有读锁，关闭时持有写锁
+	closed   bool
+	stopping chan struct{} // 开始关闭时关闭，唤醒阻塞在入队上的调用方，使其释放读锁
+	stopOnce sync.Once
+
+	enqueued  atomic.Uint64 // 已接收的日志数
+	processed atomic.Uint64 // 已写入或丢弃的日志数
+	dropped   atomic.Uint64 // 已丢弃的日志数
+	reported  uint64        // 已写入统计日志的丢弃数，只由写入协程访问
+
+	flushMu   sync.Mutex
+	flushCond *sync.Cond
+	flushers  atomic.Int32 // 正在等待 flush 的调用方数量
+}
+
+// newAsyncPipeline 创建异步写入管道并启动写入协程
+func newAsyncPipeline(root zapcore.Core, config *Config) *asyncPipeline {
+	bufferSize := config.AsyncBufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultAsyncBufferSize
+	}
+	keepLevel, err := parseLevel(config.AsyncOverflowLevel)
+	if err != nil {
+		keepLevel = zapcore.WarnLevel
+	}
+
+	p := &asyncPipeline{
+		queue:     make(chan asyncEntry, bufferSize),
+		done:      make(chan struct{}),
+		stopping:  make(chan struct{}),
+		root:      root,
+		policy:    config.AsyncOverflow,
+		keepLevel: keepLevel,
+	}
+	if p.policy == OverflowSpill {
+		p.spill = newSpillBuffer()
+	}
+	p.flushCond = sync.NewCond(&p.flushMu)
+	go p.run()
+	return p
+}
+
+// run 写入协程，队列关闭且写完后退出
+func (p *asyncPipeline) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(DefaultDropReportInterval)
+	defer ticker.Stop()
+
+	var spilled <-chan struct{}
+	if p.spill != nil {
+		spilled = p.spill.notify
+	}
+
+	for {
+		select {
+		case item, ok := <-p.queue:
+			if !ok {
+				// 队列已关闭，写完溢出到磁盘的日志后退出
+				for p.drainSpill() {
+				}
+				p.reportDropped()
+				p.wakeFlushers()
+				return
+			}
+			writeEntry(item.core, item.entry, item.fields)
+			p.markProcessed(1)
+			if len(p.queue) == 0 {
+				p.drainSpill()
+			}
+		case <-spilled:
+			// 溢出的日志比队列中的晚，队列写完后才能回放
+			if len(p.queue) == 0 {
+				p.drainSpill()
+			}
+		case <-ticker.C:
+			p.reportDropped()
+		}
+	}
+}
+
+// enqueue 把日志放入队列，队列已满时按溢出策略处理；管道已关闭时返回 false
+func (p *asyncPipeline) enqueue(item asyncEntry) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+	p.enqueued.Add(1)
+
+	// 已经有日志溢出到磁盘时，后续日志也必须溢出，保证顺序
+	if p.spill != nil {
+		if active, err := p.spill.pushIfActive(item); active {
+			if err != nil {
+				p.drop()
+			}
+			return true
+		}
+	}
+
+	select {
+	case p.queue <- item:
+		return true
+	default:
+	}
+
+	// 队列已满
+	switch p.policy {
+	case OverflowDropNewest:
+		p.drop()
+	case OverflowDropOldest:
+		select {
+		case <-p.queue:
+			p.drop()
+		default:
+		}
+		select {
+		case p.queue <- item:
+		default:
+			p.drop()
+		}
+	case OverflowDropBelowLevel:
+		if item.entry.Level < p.keepLevel {
+			p.drop()
+		} else {
+			return p.send(item)
+		}
+	case OverflowSpill:
+		if err := p.spill.push(item); err != nil {
+			p.drop()
+		}
+	default:
+		// OverflowBlock：等待写入协程腾出空间
+		return p.send(item)
+	}
+	return true
+}
+
+// send 等待队列腾出空间；管道开始关闭时放弃等待并返回 false，由调用方同步写入
+// 写入协程卡住（例如磁盘挂起）时，阻塞的调用方不能一直持有读锁，否则 close 拿不到写锁，无法按 ctx 截止
+func (p *asyncPipeline) send(item asyncEntry) bool {
+	select {
+	case p.queue <- item:
+		return true
+	case <-p.stopping:
+		p.markProcessed(1)
+		return false
+	}
+}
+
+// drainSpill 回放一批溢出到磁盘的日志，返回是否回放了日志
+func (p *asyncPipeline) drainSpill() bool {
+	if p.spill == nil {
+		return false
+	}
+	written, lost := p.spill.drain(func(item asyncEntry) {
+		writeEntry(item.core, item.entry, item.fields)
+	})
+	if lost > 0 {
+		p.dropped.Add(uint64(lost))
+	}
+	p.markProcessed(uint64(written + lost))
+	return written+lost > 0
+}
+
+// drop 丢弃一条日志
+func (p *asyncPipeline) drop() {
+	p.dropped.Add(1)
+	p.markProcessed(1)
+}
+
+// markProcessed 记录已处理的日志数并唤醒等待 flush 的调用方
+func (p *asyncPipeline) markProcessed(n uint64) {
+	if n == 0 {
+		return
+	}
+	p.processed.Add(n)
+	if p.flushers.Load() > 0 {
+		p.wakeFlushers()
+	}
+}
+
+func (p *asyncPipeline) wakeFlushers() {
+	p.flushMu.Lock()
+	p.flushCond.Broadcast()
+	p.flushMu.Unlock()
+}
+
+// reportDropped 有新的日志被丢弃时写一条 "N log entries dropped" 统计日志
+func (p *asyncPipeline) reportDropped() {
+	total := p.dropped.Load()
+	n := total - p.reported
+	if n == 0 {
+		return
+	}
+	p.reported = total
+
+	entry := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Now(),
+		Message: fmt.Sprintf("%d log entries dropped", n),
+	}
+	writeEntry(p.root, entry, []zapcore.Field{
+		zap.Uint64("dropped", n),
+		zap.Uint64("dropped_total", total),
+		zap.String("overflow_policy", string(p.policy)),
+	})
+}
+
+// flush 等待此前接收的日志全部写完（或被丢弃）
+func (p *asyncPipeline) flush() {
+	target := p.enqueued.Load()
+
+	p.flushers.Add(1)
+	defer p.flushers.Add(-1)
+
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+	for p.processed.Load() < target {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		p.flushCond.Wait()
+	}
+}
+
+// close 停止接收新日志，在 ctx 截止前等待队列中的日志写完
+func (p *asyncPipeline) close(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stopping) })
+
+	// 入队的调用方可能卡在溢出文件的写入上，拿写锁也不能超过 ctx 的截止时间
+	// 队列关闭后写入协程才会退出，所以只需等待 done
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.closed {
+			p.closed = true
+			close(p.queue)
+		}
+	}()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		pending := p.enqueued.Load() - p.processed.Load()
+		return fmt.Errorf("%d pending log entries not written: %w", pending, ctx.Err())
+	}
+}
+
+// writeEntry 按底层 core 各自的级别过滤写入一条日志
+func writeEntry(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) {
+	if ce := core.Check(entry, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// asyncCore 异步写入的 zapcore.Core
+// 日志的时间、调用者和堆栈在调用时由 zap 确定，入队后由管道的写入协程编码和写入
+type asyncCore struct {
+	zapcore.Core
+	pipeline *asyncPipeline
+}
+
+// With 实现 zapcore.Core，派生的 core 共享同一个管道
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncCore{
+		Core:     c.Core.With(fields),
+		pipeline: c.pipeline,
+	}
+}
+
+// Check 实现 zapcore.Core
+func (c *asyncCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write 实现 zapcore.Core
+func (c *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	// Panic/Fatal 之后进程会中断，先写完队列中已有的日志再同步写入
+	if entry.Level > zapcore.ErrorLevel {
+		c.pipeline.flush()
+		writeEntry(c.Core, entry, fields)
+		return nil
+	}
+
+	// 调用方返回后可能修改字段引用的值（以及 fields 的底层数组），入队前拍快照，日志反映调用时的状态
+	if !c.pipeline.enqueue(asyncEntry{core: c.Core, entry: entry, fields: freezeFields(fields)}) {
+		// 日志器已关闭，同步写入
+		writeEntry(c.Core, entry, fields)
+	}
+	return nil
+}
+
+// Sync 实现 zapcore.Core，先写完队列中的日志再刷新底层 core
+func (c *asyncCore) Sync() error {
+	c.pipeline.flush()
+	return c.Core.Sync()
+}
@@ -0,0 +1,438 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// blockingCore 在 release 之前阻塞所有写入，写入协程因此停在第一条日志上，队列可以被确定地写满
+type blockingCore struct {
+	zapcore.Core
+	started chan struct{} // 第一次写入开始时收到通知
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newBlockingCore() (*blockingCore, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return &blockingCore{Core: core, started: make(chan struct{}, 1), gate: make(chan struct{})}, logs
+}
+
+func (c *blockingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *blockingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	select {
+	case c.started <- struct{}{}:
+	default:
+	}
+	<-c.gate
+	return c.Core.Write(entry, fields)
+}
+
+func (c *blockingCore) release() {
+	c.once.Do(func() { close(c.gate) })
+}
+
+func testEntry(core zapcore.Core, level zapcore.Level, i int, fields ...zapcore.Field) asyncEntry {
+	return asyncEntry{
+		core:   core,
+		entry:  zapcore.Entry{Level: level, Time: time.Now(), Message: strconv.Itoa(i)},
+		fields: fields,
+	}
+}
+
+// newFullPipeline 创建容量为 2 的管道：日志 0 已被写入协程取出并阻塞，日志 1、2 占满队列
+func newFullPipeline(t *testing.T, policy OverflowPolicy) (*asyncPipeline, *blockingCore, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := newBlockingCore()
+	p := newAsyncPipeline(core, &Config{AsyncBufferSize: 2, AsyncOverflow: policy, AsyncOverflowLevel: "warn"})
+	t.Cleanup(func() {
+		core.release()
+		p.close(context.Background())
+	})
+
+	p.enqueue(testEntry(core, zapcore.InfoLevel, 0))
+	select {
+	case <-core.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer did not pick up the first entry")
+	}
+	p.enqueue(testEntry(core, zapcore.InfoLevel, 1))
+	p.enqueue(testEntry(core, zapcore.InfoLevel, 2))
+	return p, core, logs
+}
+
+// messages 返回写入的日志内容，不含 "N log entries dropped" 统计日志
+func messages(logs *observer.ObservedLogs) []string {
+	var msgs []string
+	for _, entry := range logs.All() {
+		if !strings.HasSuffix(entry.Message, "log entries dropped") {
+			msgs = append(msgs, entry.Message)
+		}
+	}
+	return msgs
+}
+
+func TestAsyncOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		levels  []zapcore.Level // 日志 3、4 的级别
+		blocks  bool            // 日志 4 入队时是否等待写入协程
+		want    []string
+		dropped uint64
+	}{
+		{OverflowBlock, []zapcore.Level{zapcore.InfoLevel, zapcore.InfoLevel}, true, []string{"0", "1", "2", "3", "4"}, 0},
+		{OverflowDropNewest, []zapcore.Level{zapcore.InfoLevel, zapcore.InfoLevel}, false, []string{"0", "1", "2"}, 2},
+		{OverflowDropOldest, []zapcore.Level{zapcore.InfoLevel, zapcore.InfoLevel}, false, []string{"0", "3", "4"}, 2},
+		{OverflowDropBelowLevel, []zapcore.Level{zapcore.InfoLevel, zapcore.ErrorLevel}, true, []string{"0", "1", "2", "4"}, 1},
+		{OverflowSpill, []zapcore.Level{zapcore.InfoLevel, zapcore.InfoLevel}, false, []string{"0", "1", "2", "3", "4"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			p, core, logs := newFullPipeline(t, tt.policy)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				p.enqueue(testEntry(core, tt.levels[0], 3))
+				p.enqueue(testEntry(core, tt.levels[1], 4))
+			}()
+			if tt.blocks {
+				select {
+				case <-done:
+					t.Fatal("enqueue returned while the queue was full")
+				case <-time.After(50 * time.Millisecond):
+				}
+			} else {
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					t.Fatal("enqueue blocked while the queue was full")
+				}
+			}
+
+			core.release()
+			<-done
+			p.flush()
+
+			if got := messages(logs); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("written = %v, want %v", got, tt.want)
+			}
+			if got := p.dropped.Load(); got != tt.dropped {
+				t.Errorf("dropped = %d, want %d", got, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestAsyncDroppedReportOnClose(t *testing.T) {
+	p, core, logs := newFullPipeline(t, OverflowDropNewest)
+	p.enqueue(testEntry(core, zapcore.InfoLevel, 3))
+	core.release()
+
+	if err := p.close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	reports := logs.FilterMessage("1 log entries dropped").All()
+	if len(reports) != 1 {
+		t.Fatalf("got %d drop reports, want 1", len(reports))
+	}
+	if got := reports[0].ContextMap()["dropped_total"]; got != uint64(1) {
+		t.Errorf("dropped_total = %v, want 1", got)
+	}
+}
+
+// 写入协程卡住、调用方阻塞在满队列上时，close 仍要在 ctx 截止时返回
+func TestAsyncCloseDeadlineWithBlockedProducer(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowBlock, OverflowDropBelowLevel} {
+		t.Run(string(policy), func(t *testing.T) {
+			p, core, _ := newFullPipeline(t, policy)
+
+			blocked := make(chan bool, 1)
+			go func() {
+				blocked <- p.enqueue(testEntry(core, zapcore.ErrorLevel, 3))
+			}()
+			select {
+			case <-blocked:
+				t.Fatal("enqueue returned while the queue was full")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			closed := make(chan error, 1)
+			go func() { closed <- p.close(ctx) }()
+			select {
+			case err := <-closed:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("close error = %v, want deadline exceeded", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("close ignored its deadline while a producer was blocked")
+			}
+
+			// 阻塞的调用方放弃入队，交给 asyncCore 同步写入
+			select {
+			case queued := <-blocked:
+				if queued {
+					t.Error("blocked enqueue reported success after close")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("blocked enqueue did not return after close")
+			}
+		})
+	}
+}
+
+// mutableValue 日志记录之后会被调用方修改的值
+type mutableValue struct {
+	name string
+}
+
+func (v *mutableValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", v.name)
+	return nil
+}
+
+func (v *mutableValue) String() string { return v.name }
+
+func (v *mutableValue) Error() string { return v.name }
+
+// lockedBuffer 可以被写入协程和测试并发访问的缓冲区
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Sync() error { return nil }
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// 写入协程稍后才编码日志，调用方在记录后修改的值不能出现在输出中（配合 -race 运行时也不能有数据竞争）
+func TestAsyncFieldsSnapshotAtCall(t *testing.T) {
+	var out lockedBuffer
+	encoderConfig := zapcore.EncoderConfig{MessageKey: "msg"}
+	core := &blockingCore{
+		Core:    zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), &out, zapcore.DebugLevel),
+		started: make(chan struct{}, 1),
+		gate:    make(chan struct{}),
+	}
+	t.Cleanup(core.release)
+	p := newAsyncPipeline(core, &Config{AsyncBufferSize: 16})
+	l := zap.New(&asyncCore{Core: core, pipeline: p})
+
+	buf := []byte("original")
+	m := map[string]int{"a": 1}
+	list := []string{"x", "y"}
+	value := &mutableValue{name: "alice"}
+	l.Info("snapshot",
+		zap.ByteString("bytes", buf),
+		zap.Binary("bin", buf),
+		zap.Any("map", m),
+		zap.Strings("list", list),
+		zap.Object("obj", value),
+		zap.Stringer("stringer", value),
+		zap.Error(value),
+	)
+
+	copy(buf, "MUTATED!")
+	m["a"] = 99
+	list[0] = "changed"
+	value.name = "bob"
+	core.release()
+	if err := p.close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"msg":"snapshot","bytes":"original","bin":"b3JpZ2luYWw=","map":{"a":1},"list":["x","y"],` +
+		`"obj":{"name":"alice"},"stringer":"alice","error":"alice"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestAsyncSpillKeepsFieldTypes(t *testing.T) {
+	p, core, logs := newFullPipeline(t, OverflowSpill)
+
+	// 已经溢出到磁盘后，JSON 无法表示的 NaN 也不能让日志丢失或让 flush 卡住
+	for i := 3; i < 7; i++ {
+		p.enqueue(testEntry(core, zapcore.InfoLevel, i,
+			zap.Float64("nan", math.NaN()),
+			zap.Duration("elapsed", 1500*time.Millisecond),
+			zap.Int64("big", 1<<62+1),
+		))
+	}
+	core.release()
+
+	flushed := make(chan struct{})
+	go func() {
+		p.flush()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flush did not return")
+	}
+
+	want := []string{"0", "1", "2", "3", "4", "5", "6"}
+	if got := messages(logs); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("written = %v, want %v", got, want)
+	}
+	entry := logs.All()[3]
+	fields := entry.ContextMap()
+	if got, ok := fields["elapsed"].(time.Duration); !ok || got != 1500*time.Millisecond {
+		t.Errorf("elapsed = %#v, want 1.5s", fields["elapsed"])
+	}
+	if got, ok := fields["big"].(int64); !ok || got != 1<<62+1 {
+		t.Errorf("big = %#v, want %d", fields["big"], int64(1<<62+1))
+	}
+	if got, ok := fields["nan"].(float64); !ok || !math.IsNaN(got) {
+		t.Errorf("nan = %#v, want NaN", fields["nan"])
+	}
+}
+
+type testObject struct {
+	Name  string
+	Count int
+}
+
+func (o testObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", o.Name)
+	enc.AddInt("count", o.Count)
+	return nil
+}
+
+// 溢出到磁盘再回放的日志在各种编码格式下都与未溢出的日志输出相同
+func TestSpillRecordRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 16, 8, 30, 0, 123456789, time.UTC)
+	fields := []zapcore.Field{
+		zap.Float64("nan", math.NaN()),
+		zap.Float64("inf", math.Inf(1)),
+		zap.Float32("f32", 1.5),
+		zap.Int64("big", 1<<62+1),
+		zap.Uint64("max", math.MaxUint64),
+		zap.Bool("ok", true),
+		zap.Time("ancient", time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)),
+		zap.Complex128("c", complex(1, -2)),
+	}
+	fields = append(fields, snapshotFields()...)
+	item := asyncEntry{
+		entry: zapcore.Entry{
+			Level:      zapcore.WarnLevel,
+			Time:       at,
+			LoggerName: "test",
+			Message:    "spilled",
+			Caller:     zapcore.EntryCaller{Defined: true, File: "/src/app/main.go", Line: 42, Function: "main.run"},
+			Stack:      "stack",
+		},
+		fields: fields,
+	}
+
+	line, err := encodeSpillRecord(item)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := decodeSpillRecord(line)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.entry != item.entry {
+		t.Errorf("entry = %+v, want %+v", decoded.entry, item.entry)
+	}
+
+	for name, enc := range snapshotEncoders() {
+		t.Run(name, func(t *testing.T) {
+			if got, want := encodeFields(t, enc, decoded.fields), encodeFields(t, enc, item.fields); got != want {
+				t.Errorf("replayed entry differs\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	types := make(map[string]string)
+	for _, f := range decoded.fields {
+		if f.Type == zapcore.ErrorType {
+			types[f.Key] = errorTypeName(f.Interface.(error))
+		}
+	}
+	want := map[string]string{"error": "*errors.errorString", "verbose": "*logger.verboseError", "group": "logger.groupError", "nilptr": "*logger.verboseError"}
+	for key, typeName := range want {
+		if types[key] != typeName {
+			t.Errorf("replayed %s type = %q, want %q", key, types[key], typeName)
+		}
+	}
+}
+
+func TestLoggerCloseDeadline(t *testing.T) {
+	core, _ := newBlockingCore()
+	p := newAsyncPipeline(core, &Config{AsyncBufferSize: 1024})
+	t.Cleanup(core.release)
+	l := &Logger{
+		zapLogger: zap.New(&asyncCore{Core: core, pipeline: p}),
+		state:     &loggerState{async: p},
+	}
+	for i := 0; i < 100; i++ {
+		l.Info("pending")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := l.Close(ctx)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %v after a 20ms deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "pending log entries not written") {
+		t.Errorf("Close error = %v, want pending entries and deadline exceeded", err)
+	}
+}
+
+func TestLoggerCloseWritesQueue(t *testing.T) {
+	core, logs := newBlockingCore()
+	p := newAsyncPipeline(core, &Config{AsyncBufferSize: 1024})
+	l := &Logger{
+		zapLogger: zap.New(&asyncCore{Core: core, pipeline: p}),
+		state:     &loggerState{async: p},
+	}
+	for i := 0; i < 100; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	core.release()
+
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := logs.Len(); got != 100 {
+		t.Errorf("written %d entries, want 100", got)
+	}
+	// 关闭后的日志同步写入
+	l.Info("after close")
+	if got := logs.Len(); got != 101 {
+		t.Errorf("written %d entries after close, want 101", got)
+	}
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
+)
+
+// ANSI 颜色
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+	colorCyan  = "\x1b[36m"
+)
+
+// useColor 是否为控制台输出着色：启用了 Color、控制台使用 console 编码、标准输出是终端且未设置 NO_COLOR
+func useColor(config *Config) bool {
+	if !config.Color || config.consoleEncoding() != ConsoleEncoding {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal 判断文件是否为终端，测试时替换
+// 不能只看 os.ModeCharDevice：/dev/null 也是字符设备，Windows 控制台也需要通过系统调用判断
+var isTerminal = func(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// colorConsoleEncoder 带颜色的控制台编码器
+// 级别按颜色区分，时间和调用者变暗，字段以 key=value 输出并高亮键名
+type colorConsoleEncoder struct {
+	*logfmtEncoder                 // 字段部分，包含 With 添加的字段
+	head           zapcore.Encoder // 时间、级别、名称、调用者和消息部分
+}
+
+// newColorConsoleEncoder 基于控制台编码器配置创建带颜色的编码器，级别按 style 的大小写着色
+func newColorConsoleEncoder(cfg zapcore.EncoderConfig, style LevelStyle) zapcore.Encoder {
+	headConfig := cfg
+	headConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	if style == LevelLowercase {
+		headConfig.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	}
+	headConfig.EncodeTime = dimTimeEncoder(cfg.EncodeTime)
+	headConfig.EncodeCaller = dimCallerEncoder(cfg.EncodeCaller)
+	headConfig.StacktraceKey = zapcore.OmitKey
+
+	// 字段部分只输出字段
+	fieldsConfig := cfg
+	fieldsConfig.TimeKey = zapcore.OmitKey
+	fieldsConfig.LevelKey = zapcore.OmitKey
+	fieldsConfig.NameKey = zapcore.OmitKey
+	fieldsConfig.CallerKey = zapcore.OmitKey
+	fieldsConfig.FunctionKey = zapcore.OmitKey
+	fieldsConfig.MessageKey = zapcore.OmitKey
+	fieldsConfig.StacktraceKey = zapcore.OmitKey
+	fieldsConfig.LineEnding = "\n"
+
+	fields := NewLogfmtEncoder(fieldsConfig).(*logfmtEncoder)
+	fields.keyColor = colorCyan
+
+	return &colorConsoleEncoder{
+		logfmtEncoder: fields,
+		head:          zapcore.NewConsoleEncoder(headConfig),
+	}
+}
+
+// Clone 实现 zapcore.Encoder
+func (enc *colorConsoleEncoder) Clone() zapcore.Encoder {
+	return &colorConsoleEncoder{
+		logfmtEncoder: enc.logfmtEncoder.Clone().(*logfmtEncoder),
+		head:          enc.head,
+	}
+}
+
+// EncodeEntry 实现 zapcore.Encoder
+func (enc *colorConsoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	stack := ent.Stack
+	ent.Stack = ""
+
+	line, err := enc.head.EncodeEntry(ent, nil)
+	if err != nil {
+		return nil, err
+	}
+	lineEnding := enc.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	head := bytes.TrimSuffix(line.Bytes(), []byte(zapcore.DefaultLineEnding))
+
+	out := logfmtPool.Get()
+	out.Write(head)
+	line.Free()
+
+	rest, err := enc.logfmtEncoder.EncodeEntry(ent, fields)
+	if err != nil {
+		out.Free()
+		return nil, err
+	}
+	if kv := bytes.TrimSuffix(rest.Bytes(), []byte("\n")); len(kv) > 0 {
+		out.AppendByte('\t')
+		out.Write(kv)
+	}
+	rest.Free()
+
+	if stack != "" {
+		out.AppendByte('\n')
+		out.AppendString(stack)
+	}
+	out.AppendString(lineEnding)
+	return out, nil
+}
+
+// dimTimeEncoder 时间变暗显示
+func dimTimeEncoder(base zapcore.TimeEncoder) zapcore.TimeEncoder {
+	if base == nil {
+		base = zapcore.ISO8601TimeEncoder
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		arr := &logfmtArrayEncoder{raw: true}
+		base(t, arr)
+		enc.AppendString(colorDim + arr.joined() + colorReset)
+	}
+}
+
+// dimCallerEncoder 调用者变暗显示
+func dimCallerEncoder(base zapcore.CallerEncoder) zapcore.CallerEncoder {
+	if base == nil {
+		base = zapcore.ShortCallerEncoder
+	}
+	return func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+		arr := &logfmtArrayEncoder{raw: true}
+		base(caller, arr)
+		enc.AppendString(colorDim + arr.joined() + colorReset)
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// /dev/null 是字符设备但不是终端，输出重定向到它时不应着色
+func TestIsTerminal(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, f := range []*os.File{devNull, file} {
+		if isTerminal(f) {
+			t.Errorf("isTerminal(%s) = true, want false", f.Name())
+		}
+	}
+}
+
+func TestColorOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    bool
+		terminal bool
+		noColor  string
+		encoding Encoding
+		want     bool
+	}{
+		{name: "on", color: true, terminal: true, encoding: ConsoleEncoding, want: true},
+		{name: "off", color: false, terminal: true, encoding: ConsoleEncoding},
+		// 启用时只在标准输出是终端时着色
+		{name: "auto without terminal", color: true, terminal: false, encoding: ConsoleEncoding},
+		{name: "NO_COLOR", color: true, terminal: true, noColor: "1", encoding: ConsoleEncoding},
+		{name: "json", color: true, terminal: true, encoding: JSONEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			previous := isTerminal
+			isTerminal = func(*os.File) bool { return tt.terminal }
+			defer func() { isTerminal = previous }()
+			stdout := captureStdout(t)
+
+			l, err := New(WithSetGlobal(false), WithEncoding(tt.encoding), WithColor(tt.color))
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Warn("disk almost full", zap.String("mount", "/var"))
+			l.Close(context.Background())
+
+			got := stdout()
+			if !strings.Contains(got, "disk almost full") {
+				t.Fatalf("stdout = %q, want the entry", got)
+			}
+			if colored := strings.Contains(got, "\x1b["); colored != tt.want {
+				t.Errorf("colored = %v, want %v: %q", colored, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressSlots 限制同时进行的压缩数量，所有日志器共享
+var compressSlots = make(chan struct{}, DefaultCompressWorkers)
+
+// compressionSuffixes 压缩文件的扩展名
+var compressionSuffixes = map[Compression]string{
+	CompressionGzip: ".gz",
+	CompressionZstd: ".zst",
+}
+
+// resolveCompression 确定实际使用的压缩方式：未设置 Compression 时沿用 Compress 的含义（true 为 gzip）
+func (c *Config) resolveCompression() Compression {
+	if c.Compression != "" {
+		if _, ok := compressionSuffixes[c.Compression]; !ok && c.Compression != CompressionNone {
+			// 未知的压缩方式由 Validate 报告，非严格模式下按 gzip 处理
+			return CompressionGzip
+		}
+		return c.Compression
+	}
+	if c.Compress {
+		return CompressionGzip
+	}
+	return CompressionNone
+}
+
+// trimCompressionSuffix 去掉压缩文件的扩展名，返回原文件名及是否为压缩文件
+func trimCompressionSuffix(name string) (string, bool) {
+	for _, suffix := range compressionSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix), true
+		}
+	}
+	return name, false
+}
+
+// verifyCompressed 删除原文件前校验压缩文件，测试时替换
+var verifyCompressed = verifyCompressedFile
+
+// compressLogFile 把 src 压缩为 src 加上对应扩展名的文件，校验压缩文件可以完整解压且内容一致后删除 src
+func compressLogFile(src string, compression Compression, level int) error {
+	compressSlots <- struct{}{}
+	defer func() { <-compressSlots }()
+
+	dst := src + compressionSuffixes[compression]
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return fmt.Errorf("open compressed log file: %w", err)
+	}
+
+	sum := sha256.New()
+	err = compressTo(out, io.TeeReader(in, sum), compression, level)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("compress %s: %w", src, err)
+	}
+	if err := verifyCompressed(dst, compression, sum.Sum(nil)); err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	// 压缩文件已通过校验，之后的失败不能删除它；src 已被删除（例如被配额清理）视为成功
+	in.Close()
+	if err := os.Remove(src); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// compressTo 把 r 的内容压缩写入 out 并同步到磁盘
+func compressTo(out *os.File, r io.Reader, compression Compression, level int) error {
+	enc, err := newCompressWriter(out, compression, level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(enc, r); err != nil {
+		enc.Close()
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func newCompressWriter(w io.Writer, compression Compression, level int) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionZstd:
+		if level == 0 {
+			return zstd.NewWriter(w)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	default:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	}
+}
+
+// verifyCompressedFile 完整解压 path，确认内容的 SHA-256 与原文件一致
+func verifyCompressedFile(path string, compression Compression, want []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if err := decompressTo(sum, f, compression); err != nil {
+		return fmt.Errorf("verify %s: %w", path, err)
+	}
+	if !bytes.Equal(sum.Sum(nil), want) {
+		return fmt.Errorf("verify %s: content does not match original", path)
+	}
+	return nil
+}
+
+func decompressTo(sum hash.Hash, r io.Reader, compression Compression) error {
+	switch compression {
+	case CompressionZstd:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		_, err = io.Copy(sum, dec)
+		return err
+	default:
+		dec, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		_, err = io.Copy(sum, dec)
+		return err
+	}
+}
@@ -0,0 +1,394 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfig 返回使用 constants.go 中默认值的配置
+func defaultConfig() *Config {
+	return &Config{
+		Level:         DefaultLevel,
+		Encoding:      DefaultEncoding,
+		MaxSize:       DefaultMaxSize,
+		MaxBackups:    DefaultMaxBackups,
+		MaxAge:        DefaultMaxAge,
+		Compress:      DefaultCompress,
+		Rotation:      DefaultRotation,
+		ShowCaller:    DefaultShowCaller,
+		Stacktrace:    DefaultStacktrace,
+		ConsoleOutput: DefaultConsoleOutput,
+
+		RotationInterval: DefaultRotationInterval,
+
+		AsyncBufferSize:    DefaultAsyncBufferSize,
+		AsyncOverflow:      DefaultAsyncOverflow,
+		AsyncOverflowLevel: DefaultAsyncOverflowLevel,
+	}
+}
+
+// buildConfig 在默认配置上依次应用选项，并根据 BasePath 补全各日志文件路径
+func buildConfig(options ...Option) (*Config, error) {
+	config := defaultConfig()
+
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := errors.Join(config.errs...); err != nil {
+		return nil, err
+	}
+
+	config.resolvePaths()
+	return config, nil
+}
+
+// resolvePaths 如果设置了BasePath，为未设置的各日志文件路径生成默认值，并展开路径中的占位符（{date} 除外）
+func (c *Config) resolvePaths() {
+	if c.BasePath != "" {
+		// 未设置OutputPath时自动设置OutputPath
+		if c.OutputPath == "" {
+			c.OutputPath = c.derivePath("app")
+		}
+
+		// 未设置ErrorPath时自动设置ErrorPath
+		if c.ErrorPath == "" {
+			c.ErrorPath = c.derivePath("error")
+		}
+
+		// 未设置各级别路径时自动生成各级别路径
+		if c.DebugPath == "" {
+			c.DebugPath = c.derivePath("debug")
+		}
+		if c.InfoPath == "" {
+			c.InfoPath = c.derivePath("info")
+		}
+		if c.WarnPath == "" {
+			c.WarnPath = c.derivePath("warn")
+		}
+		if c.ErrorLPath == "" {
+			c.ErrorLPath = c.derivePath("error_l")
+		}
+		if c.PanicPath == "" {
+			c.PanicPath = c.derivePath("panic")
+		}
+		if c.FatalPath == "" {
+			c.FatalPath = c.derivePath("fatal")
+		}
+	}
+
+	for _, p := range c.pathFields() {
+		*p.path = c.expandPlaceholders(*p.path, p.level)
+	}
+}
+
+// derivePath 根据 FilePattern 生成 BasePath 下的日志文件路径
+func (c *Config) derivePath(level string) string {
+	if c.FilePattern == "" {
+		return filepath.Join(c.BasePath, level+".log")
+	}
+	return c.FilePattern
+}
+
+// pathField 一个日志文件路径字段及其 {level} 占位符的值
+type pathField struct {
+	name  string
+	path  *string
+	level string
+}
+
+func (c *Config) pathFields() []pathField {
+	return []pathField{
+		{"output_path", &c.OutputPath, "app"},
+		{"error_path", &c.ErrorPath, "error"},
+		{"debug_path", &c.DebugPath, "debug"},
+		{"info_path", &c.InfoPath, "info"},
+		{"warn_path", &c.WarnPath, "warn"},
+		{"error_l_path", &c.ErrorLPath, "error_l"},
+		{"panic_path", &c.PanicPath, "panic"},
+		{"fatal_path", &c.FatalPath, "fatal"},
+	}
+}
+
+// fileOutput 一个文件输出及其捕获的级别
+type fileOutput struct {
+	name            string // 配置字段名，用于错误信息
+	path            string
+	level           zapcore.Level
+	isLevelSpecific bool // 只捕获该级别，否则捕获该级别及以上
+}
+
+// fileOutputs 返回所有已配置的文件输出
+func (c *Config) fileOutputs() []fileOutput {
+	outputPath := c.OutputPath
+	if outputPath == "stdout" {
+		outputPath = ""
+	}
+
+	all := []fileOutput{
+		// 主日志文件 - 不设下限，只受日志器的动态级别约束，SetLevel 调低级别后同样生效
+		{"output_path", outputPath, zapcore.DebugLevel, false},
+		// 错误日志单独输出（兼容旧配置） - 捕获Error及以上级别日志
+		{"error_path", c.ErrorPath, zapcore.ErrorLevel, false},
+		// 为各个级别单独创建日志文件输出 - 只捕获特定级别的日志
+		{"debug_path", c.DebugPath, zapcore.DebugLevel, true},
+		{"info_path", c.InfoPath, zapcore.InfoLevel, true},
+		{"warn_path", c.WarnPath, zapcore.WarnLevel, true},
+		{"error_l_path", c.ErrorLPath, zapcore.ErrorLevel, true},
+		{"panic_path", c.PanicPath, zapcore.PanicLevel, true},
+		{"fatal_path", c.FatalPath, zapcore.FatalLevel, true},
+	}
+
+	outputs := all[:0]
+	for _, output := range all {
+		if output.path != "" {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+// consoleEncoding 控制台输出使用的编码格式，未设置 ConsoleEncoding 时使用 Encoding
+func (c *Config) consoleEncoding() Encoding {
+	if c.ConsoleEncoding != "" {
+		return c.ConsoleEncoding
+	}
+	return c.Encoding
+}
+
+// fileEncoding 文件输出使用的编码格式，未设置 FileEncoding 时使用 Encoding
+func (c *Config) fileEncoding() Encoding {
+	if c.FileEncoding != "" {
+		return c.FileEncoding
+	}
+	return c.Encoding
+}
+
+// Validate 检查配置，一次性返回所有问题（多个错误通过 errors.Join 合并）
+// 检查项包括：未知的级别和编码（含控制台和文件各自的编码）、负数或未知的轮转参数、多个输出指向同一文件、日志目录不可写
+func (c *Config) Validate() error {
+	resolved := *c
+	resolved.resolvePaths()
+
+	var errs []error
+	if c.BasePath != "" {
+		if err := c.checkPlaceholders("file_pattern", c.FilePattern); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, p := range resolved.pathFields() {
+		if err := resolved.checkPlaceholders(p.name, *p.path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := parseLevel(resolved.Level); err != nil {
+		errs = append(errs, fmt.Errorf("level: %w", err))
+	}
+	errs = append(errs, resolved.encodingErrors()...)
+	if err := resolved.Encoder.apply(&zapcore.EncoderConfig{}); err != nil {
+		errs = append(errs, err)
+	}
+	switch resolved.Schema {
+	case "", ECS, GCP, OTel:
+	default:
+		errs = append(errs, fmt.Errorf("schema: unknown schema %q", resolved.Schema))
+	}
+	if err := resolved.Trace.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if resolved.MaxSize < 0 {
+		errs = append(errs, fmt.Errorf("max_size: must not be negative, got %d", resolved.MaxSize))
+	}
+	if resolved.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("max_backups: must not be negative, got %d", resolved.MaxBackups))
+	}
+	if resolved.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("max_age: must not be negative, got %d", resolved.MaxAge))
+	}
+	switch resolved.Compression {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		errs = append(errs, fmt.Errorf("compression: unknown compression %q", resolved.Compression))
+	}
+	switch resolved.resolveCompression() {
+	case CompressionGzip:
+		if resolved.CompressionLevel < 0 || resolved.CompressionLevel > 9 {
+			errs = append(errs, fmt.Errorf("compression_level: gzip level must be between 1 and 9 (0 means default), got %d", resolved.CompressionLevel))
+		}
+	case CompressionZstd:
+		if resolved.CompressionLevel < 0 || resolved.CompressionLevel > 22 {
+			errs = append(errs, fmt.Errorf("compression_level: zstd level must be between 1 and 22 (0 means default), got %d", resolved.CompressionLevel))
+		}
+	}
+	if resolved.DiskQuota < 0 {
+		errs = append(errs, fmt.Errorf("disk_quota: must not be negative, got %d", resolved.DiskQuota))
+	}
+	switch resolved.Rotation {
+	case "", RotateBySize, RotateByTime, RotateBySizeAndTime, RotateExternal:
+	default:
+		errs = append(errs, fmt.Errorf("rotation: unknown rotation mode %q", resolved.Rotation))
+	}
+	switch resolved.RotationInterval {
+	case "", RotateDaily, RotateHourly:
+	default:
+		errs = append(errs, fmt.Errorf("rotation_interval: unknown interval %q", resolved.RotationInterval))
+	}
+	location, err := loadLocation(resolved.RotationTimeZone)
+	if err != nil {
+		errs = append(errs, err)
+		location = time.Local
+	}
+	if resolved.AsyncBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("async_buffer_size: must not be negative, got %d", resolved.AsyncBufferSize))
+	}
+	switch resolved.AsyncOverflow {
+	case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel, OverflowSpill:
+	default:
+		errs = append(errs, fmt.Errorf("async_overflow: unknown policy %q", resolved.AsyncOverflow))
+	}
+	if resolved.AsyncOverflowLevel != "" {
+		if _, err := parseLevel(resolved.AsyncOverflowLevel); err != nil {
+			errs = append(errs, fmt.Errorf("async_overflow_level: %w", err))
+		}
+	}
+
+	// 多个输出写同一个文件时，各自的轮转会互相破坏
+	seen := make(map[string]string)
+	dirs := []string{}
+	if resolved.BasePath != "" {
+		dirs = append(dirs, resolved.BasePath)
+	}
+	now := time.Now().In(location)
+	for _, output := range resolved.fileOutputs() {
+		// 占位符无法展开的路径已经报告过，不再检查，也不能把 {base} 当作目录名
+		if resolved.checkPlaceholders(output.name, output.path) != nil {
+			continue
+		}
+		// {date} 相同的模板展开后仍然相同，按当前日期检查
+		output.path = expandDate(output.path, now)
+		key := filepath.Clean(output.path)
+		if abs, err := filepath.Abs(key); err == nil {
+			key = abs
+		}
+		if other, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: %s already points to %s", output.name, other, output.path))
+			continue
+		}
+		seen[key] = output.name
+		dirs = append(dirs, filepath.Dir(output.path))
+	}
+
+	checked := make(map[string]bool)
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if checked[dir] {
+			continue
+		}
+		checked[dir] = true
+		if err := checkWritableDir(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// encodingErrors 检查编码格式，未知的编码格式在非严格模式下按 JSON 输出
+func (c *Config) encodingErrors() []error {
+	var errs []error
+	switch c.Encoding {
+	case JSONEncoding, ConsoleEncoding, LogfmtEncoding:
+	default:
+		errs = append(errs, fmt.Errorf("encoding: unknown encoding %q", c.Encoding))
+	}
+	for _, e := range []struct {
+		name     string
+		encoding Encoding
+	}{
+		{"console_encoding", c.ConsoleEncoding},
+		{"file_encoding", c.FileEncoding},
+	} {
+		switch e.encoding {
+		case "", JSONEncoding, ConsoleEncoding, LogfmtEncoding:
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown encoding %q", e.name, e.encoding))
+		}
+	}
+	return errs
+}
+
+// checkWritableDir 检查日志目录能否创建文件，不创建任何目录或文件（目录由 New 创建）
+// 目录还不存在时检查最近的已存在的上级目录
+func checkWritableDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("log directory %s: %s is not a directory", dir, existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTDIR) {
+			return fmt.Errorf("log directory %s: %w", dir, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return nil
+		}
+		existing = parent
+	}
+	if err := checkWritable(existing); err != nil {
+		return fmt.Errorf("log directory %s is not writable: %w", dir, err)
+	}
+	return nil
+}
+
+// LoadConfig 从 YAML 或 JSON 文件加载配置，格式由扩展名（.yaml/.yml/.json）决定
+// 文件中未出现的字段使用默认值
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	config := defaultConfig()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension: %q", ext)
+	}
+
+	return config, nil
+}
+
+// NewFromFile 从配置文件创建日志实例，options 中显式指定的选项会覆盖文件中的配置
+func NewFromFile(path string, options ...Option) (*Logger, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(append([]Option{WithConfig(config)}, options...)...)
+}
@@ -0,0 +1,233 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout 把标准输出写到临时文件，返回读取已输出内容的函数
+func captureStdout(t *testing.T) (stdout func() string) {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Stdout
+	os.Stdout = file
+	t.Cleanup(func() {
+		os.Stdout = previous
+		file.Close()
+	})
+	return func() string {
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+// notADirectory 返回父目录是普通文件、无法创建的日志路径
+func notADirectory(t *testing.T) string {
+	t.Helper()
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(parent, "app.log")
+}
+
+// Validate 一次返回所有问题，每个问题一行
+func TestValidateReportsAllErrors(t *testing.T) {
+	dir := t.TempDir()
+	config := defaultConfig()
+	config.Level = "verbose"
+	config.Encoding = "xml"
+	config.FileEncoding = "yaml"
+	config.MaxSize = -1
+	config.Compression = CompressionGzip
+	config.CompressionLevel = 10
+	config.AsyncOverflow = "retry"
+	config.OutputPath = filepath.Join(dir, "app.log")
+	config.ErrorPath = filepath.Join(dir, ".", "app.log")
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	want := []string{
+		"level: unknown level: verbose",
+		`encoding: unknown encoding "xml"`,
+		`file_encoding: unknown encoding "yaml"`,
+		"max_size: must not be negative, got -1",
+		"compression_level: gzip level must be between 1 and 9 (0 means default), got 10",
+		`async_overflow: unknown policy "retry"`,
+		"error_path: output_path already points to " + config.ErrorPath,
+	}
+	if got := strings.Split(err.Error(), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() =\n%s\nwant\n%s", err, strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateCompressionLevel(t *testing.T) {
+	tests := []struct {
+		compression Compression
+		level       int
+		err         string
+	}{
+		{CompressionGzip, 0, ""},
+		{CompressionGzip, 9, ""},
+		{CompressionGzip, -1, "compression_level: gzip level must be between 1 and 9 (0 means default), got -1"},
+		{CompressionZstd, 0, ""},
+		{CompressionZstd, 22, ""},
+		{CompressionZstd, 23, "compression_level: zstd level must be between 1 and 22 (0 means default), got 23"},
+		{CompressionNone, 99, ""},
+	}
+	for _, tt := range tests {
+		config := defaultConfig()
+		config.Compression = tt.compression
+		config.CompressionLevel = tt.level
+		err := config.Validate()
+		if tt.err == "" && err != nil {
+			t.Errorf("%s level %d: unexpected error %v", tt.compression, tt.level, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%s level %d: error = %v, want %s", tt.compression, tt.level, err, tt.err)
+		}
+	}
+}
+
+func TestValidateUnwritableDirectory(t *testing.T) {
+	t.Run("not a directory", func(t *testing.T) {
+		path := notADirectory(t)
+		config := defaultConfig()
+		config.OutputPath = path
+		err := config.Validate()
+		want := "log directory " + filepath.Dir(path) + ": " + filepath.Dir(path) + " is not a directory"
+		if err == nil || err.Error() != want {
+			t.Errorf("Validate() = %v, want %s", err, want)
+		}
+	})
+	t.Run("read only", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		dir := t.TempDir()
+		if err := os.Chmod(dir, 0o500); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(dir, 0o700) })
+		config := defaultConfig()
+		config.OutputPath = filepath.Join(dir, "logs", "app.log")
+		err := config.Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), "log directory "+filepath.Join(dir, "logs")+" is not writable: ") {
+			t.Errorf("Validate() = %v, want the directory reported as not writable", err)
+		}
+	})
+}
+
+// 严格模式下 New 返回错误的配置，非严格模式下降级运行并打印 WARN
+func TestStrictModeErrorsWhereDefaultWarns(t *testing.T) {
+	tests := []struct {
+		name    string
+		options func(t *testing.T) []Option
+		err     string
+		warn    string
+	}{
+		{
+			name:    "unknown encoding",
+			options: func(t *testing.T) []Option { return []Option{WithEncoding("xml")} },
+			err:     `encoding: unknown encoding "xml"`,
+			warn:    `WARN: encoding: unknown encoding "xml". Falling back to JSON encoding.`,
+		},
+		{
+			name:    "unknown console encoding",
+			options: func(t *testing.T) []Option { return []Option{WithConsoleEncoding("yaml")} },
+			err:     `console_encoding: unknown encoding "yaml"`,
+			warn:    `WARN: console_encoding: unknown encoding "yaml". Falling back to JSON encoding.`,
+		},
+		{
+			name:    "invalid encoder config",
+			options: func(t *testing.T) []Option { return []Option{WithTimeFormat("", "Mars/Olympus")} },
+			err:     "Mars/Olympus",
+			warn:    "WARN: Invalid encoder config: ",
+		},
+		{
+			name:    "directory cannot be created",
+			options: func(t *testing.T) []Option { return []Option{WithOutputPath(notADirectory(t))} },
+			err:     "is not a directory",
+			warn:    "Falling back to console output.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithSetGlobal(false)}, tt.options(t)...)
+
+			if _, err := New(append(options, WithStrict(true))...); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("strict New() = %v, want an error containing %q", err, tt.err)
+			}
+
+			stdout := captureStdout(t)
+			l, err := New(options...)
+			if err != nil {
+				t.Fatalf("New() = %v, want a degraded logger", err)
+			}
+			defer l.Close(context.Background())
+			if got := stdout(); !strings.Contains(got, tt.warn) {
+				t.Errorf("stdout = %q, want a warning containing %q", got, tt.warn)
+			}
+		})
+	}
+}
+
+// 配置文件中拼错的字段（包括嵌套段中的）直接报错，而不是被静默忽略
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"yaml", "logger.yaml", "level: debug\nmax_sise: 10\n", "max_sise"},
+		{"yaml nested", "logger.yml", "encoder:\n  message_kee: message\n", "message_kee"},
+		{"json", "logger.json", `{"level":"debug","max_sise":10}`, `unknown field "max_sise"`},
+		{"json nested", "logger.json", `{"trace":{"disable":true}}`, `unknown field "disable"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.err) || !strings.HasPrefix(err.Error(), "parse config file "+path) {
+				t.Errorf("LoadConfig() = %v, want an error about %s", err, tt.err)
+			}
+		})
+	}
+}
+
+// 合法的配置文件照常加载，文件中没有的字段保持默认值
+func TestLoadConfigKnownFields(t *testing.T) {
+	for name, content := range map[string]string{
+		"logger.yaml": "level: debug\nencoder:\n  message_key: message\n",
+		"logger.json": `{"level":"debug","encoder":{"message_key":"message"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Level != DebugLevel || config.Encoder.MessageKey != "message" || config.MaxSize != DefaultMaxSize {
+				t.Errorf("config = %+v", config)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "time"
+
 // 日志级别
 type Level string
 
@@ -18,6 +20,67 @@ type Encoding string
 const (
 	JSONEncoding    Encoding = "json"
 	ConsoleEncoding Encoding = "console"
+	LogfmtEncoding  Encoding = "logfmt" // key=value 格式
+)
+
+// 日志字段的命名规范，为空时使用本库默认的字段名
+type Schema string
+
+const (
+	ECS  Schema = "ecs"  // Elastic Common Schema，用于 Elasticsearch
+	GCP  Schema = "gcp"  // Google Cloud Logging 的结构化日志
+	OTel Schema = "otel" // OpenTelemetry 日志数据模型
+)
+
+// 链路追踪字段的默认键名，使用 Schema 时改写为对应的字段名（自定义的键名不改写）
+const (
+	DefaultTraceIDKey    = "trace_id"
+	DefaultSpanIDKey     = "span_id"
+	DefaultTraceFlagsKey = "trace_flags"
+)
+
+// 链路追踪 ID 的输出格式
+type TraceFormat string
+
+const (
+	TraceFormatHex     TraceFormat = "hex"     // 32 位和 16 位十六进制，与 W3C traceparent 一致
+	TraceFormatDecimal TraceFormat = "decimal" // 取低 64 位的十进制，与 Datadog 一致
+)
+
+// 级别的输出样式
+type LevelStyle string
+
+const (
+	LevelCapital   LevelStyle = "capital"   // INFO
+	LevelLowercase LevelStyle = "lowercase" // info
+)
+
+// 时长的输出样式
+type DurationStyle string
+
+const (
+	DurationString  DurationStyle = "string"  // 1.5s
+	DurationSeconds DurationStyle = "seconds" // 1.5
+	DurationMillis  DurationStyle = "millis"  // 1500
+	DurationNanos   DurationStyle = "nanos"   // 1500000000
+)
+
+// 调用者的输出样式
+type CallerStyle string
+
+const (
+	CallerShort CallerStyle = "short" // logger/logger.go:42
+	CallerFull  CallerStyle = "full"  // 完整路径
+)
+
+// 预设的时间格式，EncoderConfig.TimeLayout 也可以是任意 Go 时间格式
+const (
+	TimeISO8601     = "iso8601"      // 2006-01-02T15:04:05.000Z0700
+	TimeRFC3339     = "rfc3339"      // 2006-01-02T15:04:05Z07:00
+	TimeRFC3339Nano = "rfc3339nano"  // 2006-01-02T15:04:05.999999999Z07:00
+	TimeEpoch       = "epoch"        // 秒级时间戳（浮点数）
+	TimeEpochMillis = "epoch_millis" // 毫秒级时间戳
+	TimeEpochNanos  = "epoch_nanos"  // 纳秒级时间戳
 )
 
 // 默认配置
@@ -33,3 +96,65 @@ const (
 	DefaultAsyncMode  = false // 默认不使用异步模式，保持向后兼容
 	DefaultConsoleOutput = true // 默认输出到控制台
 )
+
+// 日志文件轮转方式
+type RotationMode string
+
+const (
+	RotateBySize        RotationMode = "size"      // 文件超过 MaxSize 时轮转
+	RotateByTime        RotationMode = "time"      // 按 RotationInterval 周期轮转
+	RotateBySizeAndTime RotationMode = "size+time" // 按周期轮转，周期内超过 MaxSize 时再按序号轮转
+	RotateExternal      RotationMode = "external"  // 不自行轮转，收到 SIGHUP 或调用 Reopen 时重新打开文件（配合系统 logrotate）
+)
+
+// 按时间轮转的周期
+type RotationInterval string
+
+const (
+	RotateDaily  RotationInterval = "daily"
+	RotateHourly RotationInterval = "hourly"
+)
+
+// 文件轮转
+const (
+	DefaultRotation         = RotateBySize // 默认按大小轮转，保持向后兼容
+	DefaultRotationInterval = RotateDaily
+)
+
+// 轮转后旧文件的压缩方式
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// 压缩
+const (
+	DefaultCompressWorkers = 2 // 所有日志器同时进行的压缩数量上限
+)
+
+// 异步队列满时的处理策略
+type OverflowPolicy string
+
+const (
+	OverflowBlock          OverflowPolicy = "block"            // 等待队列腾出空间
+	OverflowDropNewest     OverflowPolicy = "drop_newest"      // 丢弃新的日志
+	OverflowDropOldest     OverflowPolicy = "drop_oldest"      // 丢弃队列中最早的日志
+	OverflowDropBelowLevel OverflowPolicy = "drop_below_level" // 丢弃低于 AsyncOverflowLevel 的日志，其余等待
+	OverflowSpill          OverflowPolicy = "spill"            // 溢出到临时磁盘缓冲，稍后按顺序写入
+)
+
+// 异步模式
+const (
+	DefaultAsyncBufferSize    = 1000             // 每个日志器异步队列的容量
+	DefaultAsyncOverflow      = OverflowBlock    // 默认队列满时等待，不丢日志
+	DefaultAsyncOverflowLevel = WarnLevel        // OverflowDropBelowLevel 默认保留 Warn 及以上
+	DefaultDropReportInterval = 10 * time.Second // 写入 "N log entries dropped" 统计日志的间隔
+)
+
+// 配置热加载
+const (
+	DefaultWatchInterval = 5 * time.Second // 配置文件轮询间隔
+)
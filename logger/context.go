@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// loggerKey 和 fieldsKey 是 context 中保存日志器和字段的键
+type (
+	loggerKey struct{}
+	fieldsKey struct{}
+)
+
+// WithContext 返回携带日志器的 context，之后可通过 FromContext 取出
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext 取出 context 中的日志器，没有时返回全局日志器
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return L()
+}
+
+// ContextWithFields 返回附加了字段的 context，Ctx 系列方法会自动输出这些字段
+// 多次调用时字段依次追加，不影响父 context
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	existing := FieldsFromContext(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext 返回 context 中附加的字段
+func FieldsFromContext(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// contextFields 依次合并 span 的链路追踪字段、context 中的字段和调用时传入的字段
+func (l *Logger) contextFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	var traceFields []zap.Field
+	if l.config != nil {
+		traceFields = l.config.Trace.fields(ctx)
+	}
+	ctxFields := FieldsFromContext(ctx)
+	if len(traceFields) == 0 && len(ctxFields) == 0 {
+		return fields
+	}
+	merged := make([]zap.Field, 0, len(traceFields)+len(ctxFields)+len(fields))
+	merged = append(merged, traceFields...)
+	merged = append(merged, ctxFields...)
+	return append(merged, fields...)
+}
+
+// 带 context 的日志方法，自动添加 span 的链路追踪字段和 context 中的字段
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		fmt.Printf("DEBUG: %s\n", msg)
+		return
+	}
+
+	l.zapLogger.Debug(msg, l.contextFields(ctx, fields)...)
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		fmt.Printf("INFO: %s\n", msg)
+		return
+	}
+
+	l.zapLogger.Info(msg, l.contextFields(ctx, fields)...)
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		fmt.Printf("WARN: %s\n", msg)
+		return
+	}
+
+	l.zapLogger.Warn(msg, l.contextFields(ctx, fields)...)
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		fmt.Printf("ERROR: %s\n", msg)
+		return
+	}
+
+	l.zapLogger.Error(msg, l.contextFields(ctx, fields)...)
+}
+
+func (l *Logger) PanicCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		panic(fmt.Sprintf("PANIC: %s", msg))
+	}
+
+	l.zapLogger.Panic(msg, l.contextFields(ctx, fields)...)
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if l == nil || l.zapLogger == nil {
+		fmt.Printf("FATAL: %s\n", msg)
+		os.Exit(1)
+	}
+
+	l.zapLogger.Fatal(msg, l.contextFields(ctx, fields)...)
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldKeys 返回字段的键名，用于检查顺序
+func fieldKeys(fields []zapcore.Field) []string {
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	return keys
+}
+
+func TestFromContextFallsBackToGlobal(t *testing.T) {
+	global, _ := newObservedLogger(defaultConfig())
+	restore := ReplaceGlobal(global)
+	defer restore()
+	l, _ := newObservedLogger(defaultConfig())
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want *Logger
+	}{
+		{"nil context", nil, global},
+		{"no logger", context.Background(), global},
+		{"nil logger", WithContext(context.Background(), nil), global},
+		{"logger", WithContext(context.Background(), l), l},
+		{"nil parent", WithContext(nil, l), l},
+		{"nested", WithContext(WithContext(context.Background(), global), l), l},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
+
+// 多次调用 ContextWithFields 按顺序追加字段，不影响父 context
+func TestContextWithFieldsMergesInOrder(t *testing.T) {
+	parent := ContextWithFields(nil, zap.String("a", "1"))
+	child := ContextWithFields(parent, zap.String("b", "2"), zap.String("c", "3"))
+	sibling := ContextWithFields(parent, zap.String("d", "4"))
+	grandchild := ContextWithFields(child, zap.String("a", "5"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"parent", parent, []string{"a"}},
+		{"child", child, []string{"a", "b", "c"}},
+		{"sibling", sibling, []string{"a", "d"}},
+		{"grandchild", grandchild, []string{"a", "b", "c", "a"}},
+		{"empty", context.Background(), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldKeys(FieldsFromContext(tt.ctx)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Ctx 方法依次输出链路追踪字段、context 中的字段和调用时传入的字段
+func TestCtxMethodsUseContextFields(t *testing.T) {
+	l, logs := newObservedLogger(&Config{Trace: TraceConfig{SpanIDKey: omitKey, TraceFlagsKey: omitKey}})
+	ctx := ContextWithFields(spanContext(trace.FlagsSampled), zap.String("request_id", "r1"))
+	ctx = ContextWithFields(ctx, zap.String("user", "alice"))
+
+	methods := map[string]func(context.Context, string, ...zap.Field){
+		"DebugCtx": l.DebugCtx,
+		"InfoCtx":  l.InfoCtx,
+		"WarnCtx":  l.WarnCtx,
+		"ErrorCtx": l.ErrorCtx,
+	}
+	for name, log := range methods {
+		log(ctx, name, zap.Int("attempt", 2))
+	}
+
+	want := []string{"trace_id", "request_id", "user", "attempt"}
+	if got := logs.Len(); got != len(methods) {
+		t.Fatalf("logged %d entries, want %d", got, len(methods))
+	}
+	for _, entry := range logs.All() {
+		if got := fieldKeys(entry.Context); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s keys = %v, want %v", entry.Message, got, want)
+		}
+	}
+}
+
+// 全局 Ctx 函数使用 context 中的日志器，没有时使用全局日志器
+func TestGlobalCtxFunctionsUseContextLogger(t *testing.T) {
+	global, globalLogs := newObservedLogger(defaultConfig())
+	restore := ReplaceGlobal(global)
+	defer restore()
+	l, logs := newObservedLogger(defaultConfig())
+
+	InfoCtx(ContextWithFields(WithContext(context.Background(), l), zap.String("user", "alice")), "scoped")
+	InfoCtx(context.Background(), "global")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "scoped" {
+		t.Errorf("context logger got %v, want the scoped entry", logs.All())
+	} else if got := logs.All()[0].ContextMap()["user"]; got != "alice" {
+		t.Errorf("user = %v, want alice", got)
+	}
+	if globalLogs.Len() != 1 || globalLogs.All()[0].Message != "global" {
+		t.Errorf("global logger got %v, want the global entry", globalLogs.All())
+	}
+}
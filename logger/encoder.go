@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderConfig 编码器配置，字段为空时使用默认值，键名设置为 "-" 表示不输出该项
+type EncoderConfig struct {
+	// 各项的键名，默认为 time、level、msg、caller、stacktrace、logger
+	TimeKey       string `json:"time_key" yaml:"time_key"`
+	LevelKey      string `json:"level_key" yaml:"level_key"`
+	MessageKey    string `json:"message_key" yaml:"message_key"`
+	CallerKey     string `json:"caller_key" yaml:"caller_key"`
+	StacktraceKey string `json:"stacktrace_key" yaml:"stacktrace_key"`
+	NameKey       string `json:"name_key" yaml:"name_key"`
+
+	// 时间格式：iso8601（默认）、rfc3339、rfc3339nano、epoch、epoch_millis、epoch_nanos 或任意 Go 时间格式
+	TimeLayout string `json:"time_layout" yaml:"time_layout"`
+	// 输出时间使用的时区，为空时使用本地时区，例如 "UTC"、"Asia/Shanghai"
+	TimeZone string `json:"time_zone" yaml:"time_zone"`
+
+	LevelStyle    LevelStyle    `json:"level_style" yaml:"level_style"`       // capital（默认）或 lowercase
+	DurationStyle DurationStyle `json:"duration_style" yaml:"duration_style"` // string（默认）、seconds、millis、nanos
+	CallerStyle   CallerStyle   `json:"caller_style" yaml:"caller_style"`     // short（默认）或 full
+}
+
+// omitKey 键名 "-" 表示不输出
+const omitKey = "-"
+
+// apply 把配置应用到 zap 的编码器配置上
+func (e EncoderConfig) apply(cfg *zapcore.EncoderConfig) error {
+	setKey := func(dst *string, key string) {
+		switch key {
+		case "":
+		case omitKey:
+			*dst = zapcore.OmitKey
+		default:
+			*dst = key
+		}
+	}
+	setKey(&cfg.TimeKey, e.TimeKey)
+	setKey(&cfg.LevelKey, e.LevelKey)
+	setKey(&cfg.MessageKey, e.MessageKey)
+	setKey(&cfg.CallerKey, e.CallerKey)
+	setKey(&cfg.StacktraceKey, e.StacktraceKey)
+	setKey(&cfg.NameKey, e.NameKey)
+
+	var errs []error
+	timeEncoder, err := e.timeEncoder()
+	if err != nil {
+		errs = append(errs, err)
+	} else if timeEncoder != nil {
+		cfg.EncodeTime = timeEncoder
+	}
+
+	switch e.LevelStyle {
+	case "", LevelCapital:
+	case LevelLowercase:
+		cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	default:
+		errs = append(errs, fmt.Errorf("encoder.level_style: unknown style %q", e.LevelStyle))
+	}
+
+	switch e.DurationStyle {
+	case "", DurationString:
+	case DurationSeconds:
+		cfg.EncodeDuration = zapcore.SecondsDurationEncoder
+	case DurationMillis:
+		cfg.EncodeDuration = zapcore.MillisDurationEncoder
+	case DurationNanos:
+		cfg.EncodeDuration = zapcore.NanosDurationEncoder
+	default:
+		errs = append(errs, fmt.Errorf("encoder.duration_style: unknown style %q", e.DurationStyle))
+	}
+
+	switch e.CallerStyle {
+	case "", CallerShort:
+	case CallerFull:
+		cfg.EncodeCaller = zapcore.FullCallerEncoder
+	default:
+		errs = append(errs, fmt.Errorf("encoder.caller_style: unknown style %q", e.CallerStyle))
+	}
+
+	return errors.Join(errs...)
+}
+
+// timeEncoder 根据 TimeLayout 和 TimeZone 生成时间编码函数，都为空时返回 nil（使用默认的 ISO8601）
+func (e EncoderConfig) timeEncoder() (zapcore.TimeEncoder, error) {
+	switch e.TimeLayout {
+	case TimeEpoch:
+		return zapcore.EpochTimeEncoder, nil
+	case TimeEpochMillis:
+		return zapcore.EpochMillisTimeEncoder, nil
+	case TimeEpochNanos:
+		return zapcore.EpochNanosTimeEncoder, nil
+	}
+	if e.TimeLayout == "" && e.TimeZone == "" {
+		return nil, nil
+	}
+
+	var location *time.Location
+	if e.TimeZone != "" {
+		loc, err := time.LoadLocation(e.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("encoder.time_zone: %w", err)
+		}
+		location = loc
+	}
+
+	layout := e.TimeLayout
+	switch layout {
+	case "", TimeISO8601:
+		layout = "2006-01-02T15:04:05.000Z0700"
+	case TimeRFC3339:
+		layout = time.RFC3339
+	case TimeRFC3339Nano:
+		layout = time.RFC3339Nano
+	}
+
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		if location != nil {
+			t = t.In(location)
+		}
+		enc.AppendString(t.Format(layout))
+	}, nil
+}
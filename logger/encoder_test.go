@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// outputKeys 返回一行 JSON 或 logfmt 日志中的键名
+func outputKeys(t *testing.T, encoding Encoding, line string) []string {
+	t.Helper()
+	var keys []string
+	switch encoding {
+	case JSONEncoding:
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		for key := range entry {
+			keys = append(keys, key)
+		}
+	case LogfmtEncoding:
+		for _, pair := range strings.Fields(line) {
+			key, _, _ := strings.Cut(pair, "=")
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 改名和设置为 "-" 的键名在 JSON 和 logfmt 的文件输出中生效
+func TestEncoderKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder EncoderConfig
+		want    []string
+	}{
+		{
+			name:    "default",
+			encoder: EncoderConfig{},
+			want:    []string{"caller", "level", "msg", "time", "user"},
+		},
+		{
+			name:    "renamed",
+			encoder: EncoderConfig{TimeKey: "ts", LevelKey: "severity", MessageKey: "message", CallerKey: "src"},
+			want:    []string{"message", "severity", "src", "ts", "user"},
+		},
+		{
+			name:    "omitted",
+			encoder: EncoderConfig{TimeKey: omitKey, LevelKey: omitKey, CallerKey: omitKey},
+			want:    []string{"msg", "user"},
+		},
+	}
+	for _, tt := range tests {
+		for _, encoding := range []Encoding{JSONEncoding, LogfmtEncoding} {
+			t.Run(tt.name+"/"+string(encoding), func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "app.log")
+				l, err := New(
+					WithSetGlobal(false),
+					WithConsoleOutput(false),
+					WithOutputPath(path),
+					WithEncoding(encoding),
+					WithEncoderConfig(tt.encoder),
+					WithCaller(true),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l.With(zap.String("user", "alice")).Info("hello")
+				if err := l.Close(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				line := strings.TrimSpace(string(data))
+				if got := outputKeys(t, encoding, line); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("keys = %v, want %v in %s", got, tt.want, line)
+				}
+			})
+		}
+	}
+}
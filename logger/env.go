@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithEnv 使用环境变量覆盖配置，变量名为 前缀 + 字段 yaml 标签的大写形式
+// 例如 prefix 为 "ZAPW" 时：ZAPW_LEVEL=debug、ZAPW_BASE_PATH=/var/log/app、ZAPW_ASYNC_MODE=true
+// 值的类型错误（例如 ZAPW_MAX_SIZE=abc）会由 New 返回
+func WithEnv(prefix string) Option {
+	return func(c *Config) {
+		if prefix != "" && !strings.HasSuffix(prefix, "_") {
+			prefix += "_"
+		}
+		if err := applyEnv(reflect.ValueOf(c).Elem(), prefix); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+}
+
+// applyEnv 按 yaml 标签把环境变量写入结构体字段，嵌套结构体的变量名以父字段名为前缀
+func applyEnv(v reflect.Value, prefix string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := prefix + strings.ToUpper(name)
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, applyEnv(v.Field(i), key+"_"))
+			continue
+		}
+
+		raw, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(v.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setEnvValue 把字符串形式的环境变量值转换为字段类型
+func setEnvValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", raw)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
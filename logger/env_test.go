@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want func(c *Config)
+		err  string
+	}{
+		{
+			name: "top level",
+			env: map[string]string{
+				"ZAPW_LEVEL":       "debug",
+				"ZAPW_BASE_PATH":   " /var/log/app ",
+				"ZAPW_ASYNC_MODE":  "true",
+				"ZAPW_MAX_SIZE":    "50",
+				"ZAPW_DISK_QUOTA":  "1073741824",
+				"ZAPW_SHOW_CALLER": "0",
+			},
+			want: func(c *Config) {
+				c.Level = DebugLevel
+				c.BasePath = "/var/log/app"
+				c.AsyncMode = true
+				c.MaxSize = 50
+				c.DiskQuota = 1 << 30
+				c.ShowCaller = false
+			},
+		},
+		{
+			name: "nested",
+			env: map[string]string{
+				"ZAPW_ENCODER_MESSAGE_KEY":   "message",
+				"ZAPW_ENCODER_TIME_LAYOUT":   time.RFC3339,
+				"ZAPW_TRACE_DISABLED":        "true",
+				"ZAPW_TRACE_TRACE_ID_PREFIX": "projects/shop/traces/",
+			},
+			want: func(c *Config) {
+				c.Encoder.MessageKey = "message"
+				c.Encoder.TimeLayout = time.RFC3339
+				c.Trace.Disabled = true
+				c.Trace.TraceIDPrefix = "projects/shop/traces/"
+			},
+		},
+		{
+			name: "type errors",
+			env: map[string]string{
+				"ZAPW_MAX_SIZE":       "abc",
+				"ZAPW_ASYNC_MODE":     "yes",
+				"ZAPW_TRACE_DISABLED": "maybe",
+				"ZAPW_LEVEL":          "warn",
+			},
+			// 其它变量照常生效，所有类型错误一起返回
+			want: func(c *Config) {
+				c.Level = WarnLevel
+			},
+			err: `env ZAPW_MAX_SIZE: invalid integer "abc"` + "\n" +
+				`env ZAPW_TRACE_DISABLED: invalid bool "maybe"` + "\n" +
+				`env ZAPW_ASYNC_MODE: invalid bool "yes"`,
+		},
+		{
+			// 前缀不带下划线时自动补上，其它前缀的变量不生效
+			name: "other prefix",
+			env:  map[string]string{"OTHER_LEVEL": "debug", "ZAPWLEVEL": "debug"},
+			want: func(c *Config) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			got := defaultConfig()
+			WithEnv("ZAPW")(got)
+			want := defaultConfig()
+			tt.want(want)
+
+			err := errors.Join(got.errs...)
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Fatalf("error = %v, want %s", err, tt.err)
+			}
+			got.errs = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("config = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// applyEnv 对 Config 中还没有用到的类型同样适用
+func TestApplyEnvValueTypes(t *testing.T) {
+	type settings struct {
+		Interval time.Duration `yaml:"interval"`
+		Workers  uint8         `yaml:"workers"`
+		Ratio    float64       `yaml:"ratio"`
+		Tags     []string      `yaml:"tags"`
+		Skipped  string        `yaml:"-"`
+		internal string
+	}
+	tests := []struct {
+		name string
+		env  map[string]string
+		want settings
+		err  string
+	}{
+		{
+			name: "valid",
+			env:  map[string]string{"APP_INTERVAL": "1m30s", "APP_WORKERS": "8", "APP_RATIO": "0.25", "APP_-": "x"},
+			want: settings{Interval: 90 * time.Second, Workers: 8, Ratio: 0.25},
+		},
+		{name: "duration", env: map[string]string{"APP_INTERVAL": "90"}, err: `env APP_INTERVAL: invalid duration "90"`},
+		{name: "overflow", env: map[string]string{"APP_WORKERS": "256"}, err: `env APP_WORKERS: invalid unsigned integer "256"`},
+		{name: "number", env: map[string]string{"APP_RATIO": "half"}, err: `env APP_RATIO: invalid number "half"`},
+		{name: "unsupported", env: map[string]string{"APP_TAGS": "a,b"}, err: `env APP_TAGS: unsupported field type []string`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			var got settings
+			err := applyEnv(reflect.ValueOf(&got).Elem(), "APP_")
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("error = %v, want %s", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("settings = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// freezeFields 在调用时为字段拍快照，异步写入协程稍后编码时不会读到调用方之后修改的值
+// 字节切片被复制；Stringer 和反射值立即求值；对象和数组记录为可以重放给任意编码器的操作；错误保留信息、详细信息、原因和类型名
+func freezeFields(fields []zapcore.Field) []zapcore.Field {
+	frozen := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		frozen[i] = freezeField(f)
+	}
+	return frozen
+}
+
+func freezeField(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		b, _ := f.Interface.([]byte)
+		f.Interface = bytes.Clone(b)
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		f.Interface = freezeObject(f.Interface.(zapcore.ObjectMarshaler))
+	case zapcore.ArrayMarshalerType:
+		f.Interface = freezeArray(f.Interface.(zapcore.ArrayMarshaler))
+	case zapcore.ReflectType:
+		if _, ok := f.Interface.(json.RawMessage); ok {
+			return f
+		}
+		raw, err := marshalReflected(f.Interface)
+		if err != nil {
+			// 与 zap 相同：无法编码时只输出 <key>Error
+			return zap.String(f.Key+"Error", err.Error())
+		}
+		f.Interface = raw
+	case zapcore.StringerType:
+		return freezeStringer(f)
+	case zapcore.ErrorType:
+		frozen, err := freezeError(f.Interface.(error))
+		if err != nil {
+			return zap.String(f.Key+"Error", err.Error())
+		}
+		f.Interface = frozen
+	}
+	return f
+}
+
+// marshalReflected 与 zap 的 JSON 编码器一样编码反射值（不转义 HTML）
+func marshalReflected(value interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+// freezeStringer 与 zap 的 encodeStringer 一致：nil 指针输出 <nil>，panic 时输出 <key>Error
+func freezeStringer(f zapcore.Field) (frozen zapcore.Field) {
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(f.Interface); v.Kind() == reflect.Ptr && v.IsNil() {
+				frozen = zap.String(f.Key, "<nil>")
+				return
+			}
+			frozen = zap.String(f.Key+"Error", fmt.Sprintf("PANIC=%v", r))
+		}
+	}()
+	return zap.String(f.Key, f.Interface.(fmt.Stringer).String())
+}
+
+// errorGroup 与 zap 识别的多错误接口相同（例如 multierr）
+type errorGroup interface {
+	Errors() []error
+}
+
+// frozenError 错误的快照，按 zap 的规则编码时输出与原错误相同的信息、详细信息和原因
+type frozenError struct {
+	msg      string
+	verbose  string // %+v 的结果，原错误没有实现 fmt.Formatter 时为空
+	typeName string // 原错误的类型名，ECS/OTel 的 error.type 使用
+}
+
+func (e *frozenError) Error() string {
+	return e.msg
+}
+
+// Format 实现 fmt.Formatter，%+v 输出原错误的详细信息
+func (e *frozenError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') && e.verbose != "" {
+		io.WriteString(s, e.verbose)
+		return
+	}
+	io.WriteString(s, e.msg)
+}
+
+// frozenErrorGroup 多错误的快照
+type frozenErrorGroup struct {
+	frozenError
+	causes []error
+}
+
+func (e *frozenErrorGroup) Errors() []error {
+	return e.causes
+}
+
+// freezeError 与 zap 的 encodeError 一致：nil 指针的信息为 <nil>，其它 panic 返回错误，由调用方输出 <key>Error
+func freezeError(err error) (frozen error, panicErr error) {
+	switch err.(type) {
+	case *frozenError, *frozenErrorGroup:
+		return err, nil
+	}
+	typeName := fmt.Sprintf("%T", err)
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(err); v.Kind() == reflect.Ptr && v.IsNil() {
+				frozen = &frozenError{msg: "<nil>", typeName: typeName}
+				return
+			}
+			frozen, panicErr = nil, fmt.Errorf("PANIC=%v", r)
+		}
+	}()
+
+	e := frozenError{msg: err.Error(), typeName: typeName}
+	switch v := err.(type) {
+	case errorGroup:
+		group := &frozenErrorGroup{frozenError: e}
+		for _, cause := range v.Errors() {
+			if cause == nil {
+				continue
+			}
+			if frozenCause, causeErr := freezeError(cause); causeErr == nil {
+				group.causes = append(group.causes, frozenCause)
+			}
+		}
+		return group, nil
+	case fmt.Formatter:
+		if verbose := fmt.Sprintf("%+v", v); verbose != e.msg {
+			e.verbose = verbose
+		}
+	}
+	return &e, nil
+}
+
+// frozenObject 记录 MarshalLogObject 对编码器的调用，重放给任意编码器时输出与原对象相同
+type frozenObject struct {
+	fields []zapcore.Field // 只包含基本类型、Namespace、frozenObject、frozenArray 和 json.RawMessage
+	err    error           // MarshalLogObject 的返回值
+}
+
+func freezeObject(m zapcore.ObjectMarshaler) *frozenObject {
+	if o, ok := m.(*frozenObject); ok {
+		return o
+	}
+	rec := &objectRecorder{}
+	err := m.MarshalLogObject(rec)
+	return &frozenObject{fields: rec.fields, err: err}
+}
+
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (o *frozenObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range o.fields {
+		switch f.Type {
+		case zapcore.ObjectMarshalerType:
+			_ = enc.AddObject(f.Key, f.Interface.(zapcore.ObjectMarshaler))
+		case zapcore.ArrayMarshalerType:
+			_ = enc.AddArray(f.Key, f.Interface.(zapcore.ArrayMarshaler))
+		case zapcore.ReflectType:
+			_ = enc.AddReflected(f.Key, f.Interface)
+		default:
+			f.AddTo(enc)
+		}
+	}
+	return o.err
+}
+
+// frozenArray 记录 MarshalLogArray 对编码器的调用，元素保存为没有键名的字段
+type frozenArray struct {
+	elems []zapcore.Field
+	err   error
+}
+
+func freezeArray(m zapcore.ArrayMarshaler) *frozenArray {
+	if a, ok := m.(*frozenArray); ok {
+		return a
+	}
+	rec := &arrayRecorder{}
+	err := m.MarshalLogArray(rec)
+	return &frozenArray{elems: rec.elems, err: err}
+}
+
+// MarshalLogArray 实现 zapcore.ArrayMarshaler
+func (a *frozenArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for _, e := range a.elems {
+		switch e.Type {
+		case zapcore.BoolType:
+			arr.AppendBool(e.Integer == 1)
+		case zapcore.ByteStringType:
+			arr.AppendByteString(e.Interface.([]byte))
+		case zapcore.Complex128Type:
+			arr.AppendComplex128(e.Interface.(complex128))
+		case zapcore.Complex64Type:
+			arr.AppendComplex64(e.Interface.(complex64))
+		case zapcore.DurationType:
+			arr.AppendDuration(time.Duration(e.Integer))
+		case zapcore.Float64Type:
+			arr.AppendFloat64(math.Float64frombits(uint64(e.Integer)))
+		case zapcore.Float32Type:
+			arr.AppendFloat32(math.Float32frombits(uint32(e.Integer)))
+		case zapcore.Int64Type:
+			arr.AppendInt64(e.Integer)
+		case zapcore.Int32Type:
+			arr.AppendInt32(int32(e.Integer))
+		case zapcore.Int16Type:
+			arr.AppendInt16(int16(e.Integer))
+		case zapcore.Int8Type:
+			arr.AppendInt8(int8(e.Integer))
+		case zapcore.StringType:
+			arr.AppendString(e.String)
+		case zapcore.TimeType, zapcore.TimeFullType:
+			arr.AppendTime(fieldTime(e))
+		case zapcore.Uint64Type:
+			arr.AppendUint64(uint64(e.Integer))
+		case zapcore.Uint32Type:
+			arr.AppendUint32(uint32(e.Integer))
+		case zapcore.Uint16Type:
+			arr.AppendUint16(uint16(e.Integer))
+		case zapcore.Uint8Type:
+			arr.AppendUint8(uint8(e.Integer))
+		case zapcore.UintptrType:
+			arr.AppendUintptr(uintptr(e.Integer))
+		case zapcore.ObjectMarshalerType:
+			_ = arr.AppendObject(e.Interface.(zapcore.ObjectMarshaler))
+		case zapcore.ArrayMarshalerType:
+			_ = arr.AppendArray(e.Interface.(zapcore.ArrayMarshaler))
+		case zapcore.ReflectType:
+			_ = arr.AppendReflected(e.Interface)
+		}
+	}
+	return a.err
+}
+
+// fieldTime 还原时间字段的值，与 zapcore.Field.AddTo 一致
+func fieldTime(f zapcore.Field) time.Time {
+	if f.Type == zapcore.TimeFullType {
+		t, _ := f.Interface.(time.Time)
+		return t
+	}
+	if loc, ok := f.Interface.(*time.Location); ok {
+		return time.Unix(0, f.Integer).In(loc)
+	}
+	return time.Unix(0, f.Integer)
+}
+
+// objectRecorder 记录对象编码器的调用
+type objectRecorder struct {
+	fields []zapcore.Field
+}
+
+func (r *objectRecorder) add(f zapcore.Field) { r.fields = append(r.fields, f) }
+
+func (r *objectRecorder) AddArray(key string, m zapcore.ArrayMarshaler) error {
+	a := freezeArray(m)
+	r.add(zapcore.Field{Key: key, Type: zapcore.ArrayMarshalerType, Interface: a})
+	return a.err
+}
+
+func (r *objectRecorder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	o := freezeObject(m)
+	r.add(zapcore.Field{Key: key, Type: zapcore.ObjectMarshalerType, Interface: o})
+	return o.err
+}
+
+func (r *objectRecorder) AddReflected(key string, value interface{}) error {
+	raw, err := marshalReflected(value)
+	if err != nil {
+		return err
+	}
+	r.add(zap.Reflect(key, raw))
+	return nil
+}
+
+func (r *objectRecorder) AddBinary(key string, v []byte) { r.add(zap.Binary(key, bytes.Clone(v))) }
+func (r *objectRecorder) AddByteString(key string, v []byte) {
+	r.add(zap.ByteString(key, bytes.Clone(v)))
+}
+func (r *objectRecorder) AddBool(key string, v bool)             { r.add(zap.Bool(key, v)) }
+func (r *objectRecorder) AddComplex128(key string, v complex128) { r.add(zap.Complex128(key, v)) }
+func (r *objectRecorder) AddComplex64(key string, v complex64)   { r.add(zap.Complex64(key, v)) }
+func (r *objectRecorder) AddDuration(key string, v time.Duration) {
+	r.add(zap.Duration(key, v))
+}
+func (r *objectRecorder) AddFloat64(key string, v float64) { r.add(zap.Float64(key, v)) }
+func (r *objectRecorder) AddFloat32(key string, v float32) { r.add(zap.Float32(key, v)) }
+func (r *objectRecorder) AddInt(key string, v int)         { r.add(zap.Int(key, v)) }
+func (r *objectRecorder) AddInt64(key string, v int64)     { r.add(zap.Int64(key, v)) }
+func (r *objectRecorder) AddInt32(key string, v int32)     { r.add(zap.Int32(key, v)) }
+func (r *objectRecorder) AddInt16(key string, v int16)     { r.add(zap.Int16(key, v)) }
+func (r *objectRecorder) AddInt8(key string, v int8)       { r.add(zap.Int8(key, v)) }
+func (r *objectRecorder) AddString(key, v string)          { r.add(zap.String(key, v)) }
+func (r *objectRecorder) AddTime(key string, v time.Time)  { r.add(zap.Time(key, v)) }
+func (r *objectRecorder) AddUint(key string, v uint)       { r.add(zap.Uint(key, v)) }
+func (r *objectRecorder) AddUint64(key string, v uint64)   { r.add(zap.Uint64(key, v)) }
+func (r *objectRecorder) AddUint32(key string, v uint32)   { r.add(zap.Uint32(key, v)) }
+func (r *objectRecorder) AddUint16(key string, v uint16)   { r.add(zap.Uint16(key, v)) }
+func (r *objectRecorder) AddUint8(key string, v uint8)     { r.add(zap.Uint8(key, v)) }
+func (r *objectRecorder) AddUintptr(key string, v uintptr) { r.add(zap.Uintptr(key, v)) }
+func (r *objectRecorder) OpenNamespace(key string)         { r.add(zap.Namespace(key)) }
+
+// arrayRecorder 记录数组编码器的调用
+type arrayRecorder struct {
+	elems []zapcore.Field
+}
+
+func (r *arrayRecorder) add(f zapcore.Field) { r.elems = append(r.elems, f) }
+
+func (r *arrayRecorder) AppendArray(m zapcore.ArrayMarshaler) error {
+	a := freezeArray(m)
+	r.add(zapcore.Field{Type: zapcore.ArrayMarshalerType, Interface: a})
+	return a.err
+}
+
+func (r *arrayRecorder) AppendObject(m zapcore.ObjectMarshaler) error {
+	o := freezeObject(m)
+	r.add(zapcore.Field{Type: zapcore.ObjectMarshalerType, Interface: o})
+	return o.err
+}
+
+func (r *arrayRecorder) AppendReflected(value interface{}) error {
+	raw, err := marshalReflected(value)
+	if err != nil {
+		return err
+	}
+	r.add(zap.Reflect("", raw))
+	return nil
+}
+
+func (r *arrayRecorder) AppendBool(v bool)             { r.add(zap.Bool("", v)) }
+func (r *arrayRecorder) AppendByteString(v []byte)     { r.add(zap.ByteString("", bytes.Clone(v))) }
+func (r *arrayRecorder) AppendComplex128(v complex128) { r.add(zap.Complex128("", v)) }
+func (r *arrayRecorder) AppendComplex64(v complex64)   { r.add(zap.Complex64("", v)) }
+func (r *arrayRecorder) AppendDuration(v time.Duration) {
+	r.add(zap.Duration("", v))
+}
+func (r *arrayRecorder) AppendFloat64(v float64) { r.add(zap.Float64("", v)) }
+func (r *arrayRecorder) AppendFloat32(v float32) { r.add(zap.Float32("", v)) }
+func (r *arrayRecorder) AppendInt(v int)         { r.add(zap.Int("", v)) }
+func (r *arrayRecorder) AppendInt64(v int64)     { r.add(zap.Int64("", v)) }
+func (r *arrayRecorder) AppendInt32(v int32)     { r.add(zap.Int32("", v)) }
+func (r *arrayRecorder) AppendInt16(v int16)     { r.add(zap.Int16("", v)) }
+func (r *arrayRecorder) AppendInt8(v int8)       { r.add(zap.Int8("", v)) }
+func (r *arrayRecorder) AppendString(v string)   { r.add(zap.String("", v)) }
+func (r *arrayRecorder) AppendTime(v time.Time)  { r.add(zap.Time("", v)) }
+func (r *arrayRecorder) AppendUint(v uint)       { r.add(zap.Uint("", v)) }
+func (r *arrayRecorder) AppendUint64(v uint64)   { r.add(zap.Uint64("", v)) }
+func (r *arrayRecorder) AppendUint32(v uint32)   { r.add(zap.Uint32("", v)) }
+func (r *arrayRecorder) AppendUint16(v uint16)   { r.add(zap.Uint16("", v)) }
+func (r *arrayRecorder) AppendUint8(v uint8)     { r.add(zap.Uint8("", v)) }
+func (r *arrayRecorder) AppendUintptr(v uintptr) { r.add(zap.Uintptr("", v)) }
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// groupError 实现 zap 识别的多错误接口
+type groupError []error
+
+func (e groupError) Error() string   { return fmt.Sprintf("%d errors", len(e)) }
+func (e groupError) Errors() []error { return e }
+
+// verboseError 与 github.com/pkg/errors 一样实现 fmt.Formatter
+type verboseError struct{ msg string }
+
+func (e *verboseError) Error() string { return e.msg }
+
+func (e *verboseError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, e.msg+"\nstack trace")
+		return
+	}
+	io.WriteString(s, e.msg)
+}
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+// snapshotFields 覆盖需要拍快照的各种字段
+func snapshotFields() []zapcore.Field {
+	at := time.Date(2026, 10, 16, 8, 30, 0, 123456789, time.UTC)
+	var nilErr *verboseError
+	return []zapcore.Field{
+		zap.String("s", "a b"),
+		zap.ByteString("bytes", []byte("中文")),
+		zap.Binary("bin", []byte{0, 1, 0xff}),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.Time("at", at),
+		zap.Stringer("stringer", time.Second),
+		zap.Stringer("panics", panicStringer{}),
+		zap.Error(errors.New("plain")),
+		zap.NamedError("verbose", &verboseError{msg: "rich"}),
+		zap.NamedError("group", groupError{errors.New("first"), nil, &verboseError{msg: "second"}}),
+		zap.NamedError("nilptr", nilErr),
+		zap.Object("obj", testObject{Name: "a b", Count: 2}),
+		zap.Dict("dict",
+			zap.Duration("elapsed", time.Second),
+			zap.Time("at", at),
+			zap.Namespace("ns"),
+			zap.Inline(testObject{Name: "inline", Count: 3}),
+			zap.Any("m", map[string]int{"x": 1}),
+		),
+		zap.Array("mixed", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			arr.AppendString("x,y")
+			arr.AppendDuration(time.Minute)
+			arr.AppendTime(at)
+			arr.AppendFloat64(math.Inf(1))
+			if err := arr.AppendObject(testObject{Name: "in array", Count: 1}); err != nil {
+				return err
+			}
+			return arr.AppendArray(zapcore.ArrayMarshalerFunc(func(inner zapcore.ArrayEncoder) error {
+				inner.AppendBool(true)
+				return inner.AppendReflected([]int{1, 2})
+			}))
+		})),
+		zap.Object("failing", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("partial", "yes")
+			return errors.New("marshal failed")
+		})),
+		zap.Any("map", map[string]string{"<k>": "v&"}),
+		zap.Any("unsupported", map[string]float64{"x": math.NaN()}),
+		zap.Inline(testObject{Name: "top", Count: 4}),
+		zap.Namespace("outer"),
+		zap.Strings("list", []string{"a", "b c"}),
+	}
+}
+
+// snapshotEncoders 日志器支持的各种编码格式
+func snapshotEncoders() map[string]zapcore.Encoder {
+	cfg := zapcore.EncoderConfig{
+		MessageKey:     "msg",
+		EncodeTime:     zapcore.TimeEncoderOfLayout("2006/01/02 15:04:05.000"),
+		EncodeDuration: zapcore.MillisDurationEncoder,
+	}
+	return map[string]zapcore.Encoder{
+		"json":    zapcore.NewJSONEncoder(cfg),
+		"console": zapcore.NewConsoleEncoder(cfg),
+		"logfmt":  NewLogfmtEncoder(cfg),
+		"color":   newColorConsoleEncoder(cfg, LevelCapital),
+	}
+}
+
+func encodeFields(t *testing.T, enc zapcore.Encoder, fields []zapcore.Field) string {
+	t.Helper()
+	buf, err := enc.Clone().EncodeEntry(zapcore.Entry{Message: "m"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestFreezeFieldsEncodeLikeOriginals(t *testing.T) {
+	fields := snapshotFields()
+	frozen := freezeFields(fields)
+	for name, enc := range snapshotEncoders() {
+		t.Run(name, func(t *testing.T) {
+			want := encodeFields(t, enc, fields)
+			if got := encodeFields(t, enc, frozen); got != want {
+				t.Errorf("frozen fields encode differently\n got: %s\nwant: %s", got, want)
+			}
+			// 快照再次拍快照不变
+			if got := encodeFields(t, enc, freezeFields(frozen)); got != want {
+				t.Errorf("refrozen fields encode differently\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
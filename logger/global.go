@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"sync"
 
 	"go.uber.org/zap"
@@ -13,16 +14,38 @@ var (
 
 // InitGlobal 初始化全局日志器
 func InitGlobal(options ...Option) error {
+	// 不能直接 append 到 options：调用方传入 opts... 时会写进调用方的底层数组
+	_, err := New(append(options[:len(options):len(options)], WithSetGlobal(true))...)
+	return err
+}
+
+// ReplaceGlobal 替换全局日志器，同时替换 zap.L() 和 zap.S()，返回恢复之前日志器的函数
+// 常用于测试：defer logger.ReplaceGlobal(testLogger)()
+func ReplaceGlobal(logger *Logger) (restore func()) {
 	globalMutex.Lock()
 	defer globalMutex.Unlock()
 
-	logger, err := New(options...)
-	if err != nil {
-		return err
+	previous := globalLogger
+	restoreZap := setGlobal(logger)
+	return func() {
+		globalMutex.Lock()
+		defer globalMutex.Unlock()
+
+		globalLogger = previous
+		restoreZap()
 	}
+}
 
+// setGlobal 设置全局日志器并同步 zap 的全局日志器，调用方需要持有 globalMutex
+func setGlobal(logger *Logger) (restoreZap func()) {
 	globalLogger = logger
-	return nil
+
+	zapLogger := zap.NewNop()
+	if logger != nil && logger.zapLogger != nil {
+		// 直接通过 zap.L() 调用时没有包装层，抵消 New 中添加的 caller skip
+		zapLogger = logger.zapLogger.WithOptions(zap.AddCallerSkip(-2))
+	}
+	return zap.ReplaceGlobals(zapLogger)
 }
 
 // L 获取全局日志器（如果未初始化则返回控制台日志器）
@@ -34,6 +57,7 @@ func L() *Logger {
 		// 返回一个安全的控制台日志器
 		return &Logger{
 			zapLogger: zap.NewExample(), // 使用示例日志器作为回退
+			config:    defaultConfig(),
 		}
 	}
 
@@ -50,6 +74,27 @@ func SyncGlobal() {
 	}
 }
 
+// Shutdown 关闭全局日志器，适合在进程退出前调用
+// 在 ctx 截止前写完异步队列中的日志并停止写入协程，然后同步并关闭所有日志文件
+func Shutdown(ctx context.Context) error {
+	return L().Close(ctx)
+}
+
+// Reopen 重新打开全局日志器的所有日志文件，用于外部程序（如 logrotate）移走文件之后
+func Reopen() error {
+	return L().Reopen()
+}
+
+// SetLevel 动态调整全局日志器的日志级别
+func SetLevel(level Level) error {
+	return L().SetLevel(level)
+}
+
+// GetLevel 获取全局日志器当前的日志级别
+func GetLevel() Level {
+	return L().Level()
+}
+
 // 全局日志方法 - 基础日志
 
 // Debug 全局Debug级别日志
@@ -82,6 +127,38 @@ func Fatal(msg string, fields ...zap.Field) {
 	L().Fatal(msg, fields...)
 }
 
+// 全局日志方法 - 带 context 的日志，使用 context 中的日志器（没有时使用全局日志器）并添加 context 中的字段
+
+// DebugCtx 全局带 context 的Debug级别日志
+func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).DebugCtx(ctx, msg, fields...)
+}
+
+// InfoCtx 全局带 context 的Info级别日志
+func InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).InfoCtx(ctx, msg, fields...)
+}
+
+// WarnCtx 全局带 context 的Warn级别日志
+func WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).WarnCtx(ctx, msg, fields...)
+}
+
+// ErrorCtx 全局带 context 的Error级别日志
+func ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).ErrorCtx(ctx, msg, fields...)
+}
+
+// PanicCtx 全局带 context 的Panic级别日志
+func PanicCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).PanicCtx(ctx, msg, fields...)
+}
+
+// FatalCtx 全局带 context 的Fatal级别日志
+func FatalCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).FatalCtx(ctx, msg, fields...)
+}
+
 // 全局日志方法 - 格式化日志
 
 // Debugf 全局格式化Debug级别日志
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// messagesOf 返回 observer 收到的所有日志消息
+func messagesOf(logs *observer.ObservedLogs) []string {
+	var messages []string
+	for _, entry := range logs.TakeAll() {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+// ReplaceGlobal 返回的函数把 L()、zap.L() 和 zap.S() 都恢复为替换前的日志器，嵌套替换按相反顺序恢复
+func TestReplaceGlobalRestoresPrevious(t *testing.T) {
+	previous, previousLogs := newObservedLogger(defaultConfig())
+	defer ReplaceGlobal(previous)()
+	first, firstLogs := newObservedLogger(defaultConfig())
+	second, secondLogs := newObservedLogger(defaultConfig())
+
+	restoreFirst := ReplaceGlobal(first)
+	restoreSecond := ReplaceGlobal(second)
+	zap.L().Info("second")
+	zap.S().Info("second sugared")
+	if L() != second {
+		t.Errorf("L() = %p, want the second logger %p", L(), second)
+	}
+
+	restoreSecond()
+	zap.L().Info("first")
+	if L() != first {
+		t.Errorf("L() after restoring = %p, want the first logger %p", L(), first)
+	}
+
+	restoreFirst()
+	zap.L().Info("previous")
+	zap.S().Info("previous sugared")
+	if L() != previous {
+		t.Errorf("L() after restoring twice = %p, want the previous logger %p", L(), previous)
+	}
+
+	for _, tt := range []struct {
+		name string
+		logs *observer.ObservedLogs
+		want []string
+	}{
+		{"previous", previousLogs, []string{"previous", "previous sugared"}},
+		{"first", firstLogs, []string{"first"}},
+		{"second", secondLogs, []string{"second", "second sugared"}},
+	} {
+		if got := messagesOf(tt.logs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s logger got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 替换为 nil 时 zap.L() 不输出，恢复后重新输出到之前的日志器
+func TestReplaceGlobalNil(t *testing.T) {
+	previous, logs := newObservedLogger(defaultConfig())
+	defer ReplaceGlobal(previous)()
+
+	restore := ReplaceGlobal(nil)
+	zap.L().Info("dropped")
+	restore()
+	zap.L().Info("kept")
+
+	if got := messagesOf(logs); !reflect.DeepEqual(got, []string{"kept"}) {
+		t.Errorf("messages = %v, want [kept]", got)
+	}
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// levelHandler 查看和修改日志级别的 HTTP 处理器
+type levelHandler struct {
+	logger func() *Logger // 每次请求时获取目标日志器，保证全局日志器被替换后依然生效
+
+	mu       sync.Mutex
+	timer    *time.Timer // 自动恢复定时器，nil 表示没有待恢复的修改
+	gen      uint64      // 每次修改递增，用于丢弃过期的定时器回调
+	revertTo Level
+	revertAt time.Time
+}
+
+// maxLevelRequestSize 级别接口请求体的最大字节数
+const maxLevelRequestSize = 1 << 20
+
+// levelPayload 级别接口的请求和响应体
+type levelPayload struct {
+	Level    Level      `json:"level"`
+	TTL      string     `json:"ttl,omitempty"`
+	RevertTo Level      `json:"revert_to,omitempty"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// LevelHandler 返回查看和修改全局日志级别的 HTTP 处理器，适合挂载到管理端口
+//
+// GET 返回当前级别，例如 {"level":"info"}
+// PUT/POST 修改级别，请求体支持 JSON（{"level":"debug","ttl":"10m"}）或表单（level=debug&ttl=10m），
+// ttl 可选，到期后自动恢复为修改前的级别
+func LevelHandler() http.Handler {
+	return &levelHandler{logger: L}
+}
+
+// LevelHandler 返回查看和修改该日志器级别的 HTTP 处理器
+func (l *Logger) LevelHandler() http.Handler {
+	return &levelHandler{logger: func() *Logger { return l }}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeState(w, http.StatusOK)
+	case http.MethodPut, http.MethodPost:
+		level, ttl, err := decodeLevelRequest(r)
+		if err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := h.setLevel(level, ttl); err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.writeState(w, http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// setLevel 修改级别，ttl 大于0时到期自动恢复
+func (h *levelHandler) setLevel(level Level, ttl time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	target := h.logger()
+	previous := target.Level()
+	if err := target.SetLevel(level); err != nil {
+		return err
+	}
+
+	// 已有待恢复的修改时，恢复目标保持为最初的级别
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+		previous = h.revertTo
+	}
+	h.gen++
+
+	if ttl > 0 {
+		gen := h.gen
+		h.revertTo = previous
+		h.revertAt = time.Now().Add(ttl)
+		h.timer = time.AfterFunc(ttl, func() {
+			h.revert(gen)
+		})
+	}
+	return nil
+}
+
+// revert 定时器到期后恢复到修改前的级别
+func (h *levelHandler) revert(gen uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if gen != h.gen || h.timer == nil {
+		return
+	}
+	h.timer = nil
+	_ = h.logger().SetLevel(h.revertTo)
+}
+
+func (h *levelHandler) writeState(w http.ResponseWriter, status int) {
+	h.mu.Lock()
+	payload := levelPayload{Level: h.logger().Level()}
+	if h.timer != nil {
+		revertAt := h.revertAt
+		payload.RevertTo = h.revertTo
+		payload.RevertAt = &revertAt
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// decodeLevelRequest 从 JSON 或表单请求体中解析级别和 ttl
+// 与 zap 的 AtomicLevel.ServeHTTP 一样，请求体以 { 开头时按 JSON 解析，不要求 Content-Type 为 application/json，
+// 例如 curl -d '{"level":"debug"}' 默认发送的是表单类型
+func decodeLevelRequest(r *http.Request) (Level, time.Duration, error) {
+	var payload levelPayload
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLevelRequestSize))
+	if err != nil {
+		return "", 0, fmt.Errorf("read request body: %w", err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" || bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return "", 0, fmt.Errorf("invalid JSON body: %w", err)
+		}
+	} else {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err := r.ParseForm(); err != nil {
+			return "", 0, fmt.Errorf("invalid form body: %w", err)
+		}
+		payload.Level = Level(r.Form.Get("level"))
+		payload.TTL = r.Form.Get("ttl")
+	}
+
+	if payload.Level == "" {
+		return "", 0, errors.New("missing level")
+	}
+	if _, err := parseLevel(payload.Level); err != nil {
+		return "", 0, err
+	}
+
+	var ttl time.Duration
+	if payload.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(payload.TTL)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid ttl: %w", err)
+		}
+		if ttl < 0 {
+			return "", 0, fmt.Errorf("invalid ttl: %s", payload.TTL)
+		}
+	}
+	return payload.Level, ttl, nil
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
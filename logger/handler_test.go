@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandlerRequestBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		want        Level
+	}{
+		{"json", "application/json", `{"level":"debug","ttl":"10m"}`, http.StatusOK, DebugLevel},
+		{"json with charset", "application/json; charset=utf-8", `{"level":"warn"}`, http.StatusOK, WarnLevel},
+		// curl -d 默认使用表单类型
+		{"json sent as form", "application/x-www-form-urlencoded", `{"level":"debug","ttl":"10m"}`, http.StatusOK, DebugLevel},
+		{"json without content type", "", ` {"level":"error"}`, http.StatusOK, ErrorLevel},
+		{"form", "application/x-www-form-urlencoded", "level=debug&ttl=10m", http.StatusOK, DebugLevel},
+		{"invalid json", "application/x-www-form-urlencoded", `{"level":`, http.StatusBadRequest, InfoLevel},
+		{"missing level", "application/json", `{}`, http.StatusBadRequest, InfoLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := New(WithLevel(InfoLevel), WithSetGlobal(false))
+			if err != nil {
+				t.Fatal(err)
+			}
+			handler := l.LevelHandler()
+
+			req := httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body)
+			}
+			if got := l.Level(); got != tt.want {
+				t.Errorf("level = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
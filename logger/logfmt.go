@@ -0,0 +1,497 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder key=value 格式（logfmt）的 zapcore.Encoder
+// 嵌套对象和 OpenNamespace 展开为以点号连接的键（user.name=alice），数组编码为 [a,b,c]
+// 值中包含空白、=、引号、控制字符或无效 UTF-8 时加引号并转义，中文等 UTF-8 字符原样输出
+type logfmtEncoder struct {
+	*zapcore.EncoderConfig
+	buf    *buffer.Buffer
+	prefix string // 嵌套对象和命名空间的键前缀
+	sep    byte   // 键值对之间的分隔符，顶层为空格，数组中的对象为逗号
+
+	keyColor string // 键名的 ANSI 颜色，仅用于带颜色的控制台输出
+}
+
+// NewLogfmtEncoder 创建 logfmt 编码器
+func NewLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{
+		EncoderConfig: &cfg,
+		buf:           logfmtPool.Get(),
+		sep:           ' ',
+	}
+}
+
+// Clone 实现 zapcore.Encoder
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{
+		EncoderConfig: enc.EncoderConfig,
+		buf:           logfmtPool.Get(),
+		prefix:        enc.prefix,
+		sep:           enc.sep,
+		keyColor:      enc.keyColor,
+	}
+	clone.buf.Write(enc.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry 实现 zapcore.Encoder
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{
+		EncoderConfig: enc.EncoderConfig,
+		buf:           logfmtPool.Get(),
+		sep:           ' ',
+		keyColor:      enc.keyColor,
+	}
+
+	if enc.TimeKey != "" && enc.TimeKey != zapcore.OmitKey {
+		final.addPrimitive(enc.TimeKey, func(arr *logfmtArrayEncoder) {
+			if enc.EncodeTime != nil {
+				enc.EncodeTime(ent.Time, arr)
+			} else {
+				arr.AppendString(ent.Time.Format(time.RFC3339Nano))
+			}
+		})
+	}
+	if enc.LevelKey != "" && enc.LevelKey != zapcore.OmitKey {
+		final.addPrimitive(enc.LevelKey, func(arr *logfmtArrayEncoder) {
+			if enc.EncodeLevel != nil {
+				enc.EncodeLevel(ent.Level, arr)
+			} else {
+				arr.AppendString(ent.Level.String())
+			}
+		})
+	}
+	if ent.LoggerName != "" && enc.NameKey != "" && enc.NameKey != zapcore.OmitKey {
+		final.addPrimitive(enc.NameKey, func(arr *logfmtArrayEncoder) {
+			if enc.EncodeName != nil {
+				enc.EncodeName(ent.LoggerName, arr)
+			} else {
+				arr.AppendString(ent.LoggerName)
+			}
+		})
+	}
+	if ent.Caller.Defined {
+		if enc.CallerKey != "" && enc.CallerKey != zapcore.OmitKey {
+			final.addPrimitive(enc.CallerKey, func(arr *logfmtArrayEncoder) {
+				if enc.EncodeCaller != nil {
+					enc.EncodeCaller(ent.Caller, arr)
+				} else {
+					arr.AppendString(ent.Caller.TrimmedPath())
+				}
+			})
+		}
+		if enc.FunctionKey != "" && enc.FunctionKey != zapcore.OmitKey {
+			final.AddString(enc.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if enc.MessageKey != "" && enc.MessageKey != zapcore.OmitKey {
+		final.AddString(enc.MessageKey, ent.Message)
+	}
+
+	if enc.buf.Len() > 0 {
+		final.separate()
+		final.buf.Write(enc.buf.Bytes())
+	}
+	final.prefix = enc.prefix
+	for _, field := range fields {
+		field.AddTo(final)
+	}
+	final.prefix = ""
+
+	if ent.Stack != "" && enc.StacktraceKey != "" && enc.StacktraceKey != zapcore.OmitKey {
+		final.AddString(enc.StacktraceKey, ent.Stack)
+	}
+
+	lineEnding := enc.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	final.buf.AppendString(lineEnding)
+	return final.buf, nil
+}
+
+// separate 在键值对之间写分隔符
+func (enc *logfmtEncoder) separate() {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(enc.sep)
+	}
+}
+
+// inArray 是否为数组中的对象，此时键值对以逗号分隔，整个对象写在 {} 中
+func (enc *logfmtEncoder) inArray() bool {
+	return enc.sep == ','
+}
+
+// addKey 写入 "key="
+func (enc *logfmtEncoder) addKey(key string) {
+	enc.separate()
+	key = enc.prefix + key
+	if enc.inArray() {
+		// 数组中对象的键名不能包含分隔符
+		key = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(",[]{}", r) {
+				return '_'
+			}
+			return r
+		}, key)
+	}
+	if enc.keyColor != "" {
+		enc.buf.AppendString(enc.keyColor)
+		appendLogfmtKey(enc.buf, key)
+		enc.buf.AppendString(colorReset)
+	} else {
+		appendLogfmtKey(enc.buf, key)
+	}
+	enc.buf.AppendByte('=')
+}
+
+// addValue 写入一个键值对，值按需加引号
+// 数组中的对象与 appendElem 一样，值包含 ,[]{} 时也加引号，避免与相邻的键值对和外层的括号混淆
+func (enc *logfmtEncoder) addValue(key, value string) {
+	enc.addKey(key)
+	if enc.inArray() && strings.ContainsAny(value, ",[]{}") {
+		appendQuotedLogfmtValue(enc.buf, value)
+		return
+	}
+	appendLogfmtValue(enc.buf, value)
+}
+
+// addPrimitive 使用 zap 的 EncodeTime、EncodeLevel 等函数生成值
+func (enc *logfmtEncoder) addPrimitive(key string, encode func(*logfmtArrayEncoder)) {
+	arr := &logfmtArrayEncoder{EncoderConfig: enc.EncoderConfig, raw: true}
+	encode(arr)
+	enc.addValue(key, arr.joined())
+}
+
+// AddArray 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	arr := &logfmtArrayEncoder{EncoderConfig: enc.EncoderConfig}
+	err := marshaler.MarshalLogArray(arr)
+	if enc.inArray() {
+		// 与 AppendArray 一致，数组中对象的数组字段原样写入，元素已经按需加了引号
+		enc.addKey(key)
+		enc.buf.AppendString("[" + arr.joined() + "]")
+		return err
+	}
+	enc.addValue(key, "["+arr.joined()+"]")
+	return err
+}
+
+// AddObject 实现 zapcore.ObjectEncoder，嵌套对象展开为 key.field=value
+func (enc *logfmtEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := marshaler.MarshalLogObject(enc)
+	enc.prefix = prefix
+	return err
+}
+
+// AddBinary 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddBinary(key string, value []byte) {
+	enc.addValue(key, base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddByteString(key string, value []byte) {
+	enc.addValue(key, string(value))
+}
+
+// AddBool 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddBool(key string, value bool) {
+	enc.addValue(key, strconv.FormatBool(value))
+}
+
+// AddComplex128 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddComplex128(key string, value complex128) {
+	enc.addValue(key, formatComplex(value, 64))
+}
+
+// AddComplex64 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddComplex64(key string, value complex64) {
+	enc.addValue(key, formatComplex(complex128(value), 32))
+}
+
+// AddDuration 实现 zapcore.ObjectEncoder，按 EncodeDuration 编码，未设置时为 1.5s 这样的字符串
+func (enc *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	enc.addPrimitive(key, func(arr *logfmtArrayEncoder) {
+		arr.AppendDuration(value)
+	})
+}
+
+// AddFloat64 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddFloat64(key string, value float64) {
+	enc.addValue(key, formatFloat(value, 64))
+}
+
+// AddFloat32 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddFloat32(key string, value float32) {
+	enc.addValue(key, formatFloat(float64(value), 32))
+}
+
+// AddInt 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddInt(key string, value int) { enc.AddInt64(key, int64(value)) }
+
+// AddInt64 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddInt64(key string, value int64) {
+	enc.addKey(key)
+	enc.buf.AppendInt(value)
+}
+
+// AddInt32 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddInt32(key string, value int32) { enc.AddInt64(key, int64(value)) }
+
+// AddInt16 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddInt16(key string, value int16) { enc.AddInt64(key, int64(value)) }
+
+// AddInt8 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddInt8(key string, value int8) { enc.AddInt64(key, int64(value)) }
+
+// AddString 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddString(key, value string) {
+	enc.addValue(key, value)
+}
+
+// AddTime 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddTime(key string, value time.Time) {
+	enc.addPrimitive(key, func(arr *logfmtArrayEncoder) {
+		arr.AppendTime(value)
+	})
+}
+
+// AddUint 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUint(key string, value uint) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint64 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUint64(key string, value uint64) {
+	enc.addKey(key)
+	enc.buf.AppendUint(value)
+}
+
+// AddUint32 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUint32(key string, value uint32) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint16 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUint16(key string, value uint16) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint8 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUint8(key string, value uint8) { enc.AddUint64(key, uint64(value)) }
+
+// AddUintptr 实现 zapcore.ObjectEncoder
+func (enc *logfmtEncoder) AddUintptr(key string, value uintptr) { enc.AddUint64(key, uint64(value)) }
+
+// AddReflected 实现 zapcore.ObjectEncoder，值编码为紧凑的 JSON
+func (enc *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	enc.addValue(key, string(data))
+	return nil
+}
+
+// OpenNamespace 实现 zapcore.ObjectEncoder，之后的字段键名加上 key. 前缀
+func (enc *logfmtEncoder) OpenNamespace(key string) {
+	enc.prefix += key + "."
+}
+
+// logfmtArrayEncoder 把数组元素（以及 EncodeTime 等函数输出的值）收集为字符串
+type logfmtArrayEncoder struct {
+	*zapcore.EncoderConfig
+	elems []string
+	raw   bool // 收集单个值时不给元素加引号，由外层统一处理
+}
+
+func (arr *logfmtArrayEncoder) joined() string {
+	return strings.Join(arr.elems, ",")
+}
+
+// appendElem 添加一个元素，包含分隔符的元素加引号，避免与相邻元素混淆
+func (arr *logfmtArrayEncoder) appendElem(value string) {
+	if !arr.raw && (needsLogfmtQuoting(value) || strings.ContainsAny(value, ",[]{}")) {
+		value = strconv.Quote(value)
+	}
+	arr.elems = append(arr.elems, value)
+}
+
+func (arr *logfmtArrayEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	inner := &logfmtArrayEncoder{EncoderConfig: arr.EncoderConfig}
+	err := marshaler.MarshalLogArray(inner)
+	arr.elems = append(arr.elems, "["+inner.joined()+"]")
+	return err
+}
+
+func (arr *logfmtArrayEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	obj := &logfmtEncoder{EncoderConfig: arr.EncoderConfig, buf: logfmtPool.Get(), sep: ','}
+	defer obj.buf.Free()
+	err := marshaler.MarshalLogObject(obj)
+	arr.elems = append(arr.elems, "{"+obj.buf.String()+"}")
+	return err
+}
+
+func (arr *logfmtArrayEncoder) AppendReflected(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	arr.elems = append(arr.elems, string(data))
+	return nil
+}
+
+func (arr *logfmtArrayEncoder) AppendBool(v bool) {
+	arr.elems = append(arr.elems, strconv.FormatBool(v))
+}
+func (arr *logfmtArrayEncoder) AppendByteString(v []byte) { arr.appendElem(string(v)) }
+func (arr *logfmtArrayEncoder) AppendComplex128(v complex128) {
+	arr.elems = append(arr.elems, formatComplex(v, 64))
+}
+func (arr *logfmtArrayEncoder) AppendComplex64(v complex64) {
+	arr.elems = append(arr.elems, formatComplex(complex128(v), 32))
+}
+func (arr *logfmtArrayEncoder) AppendFloat64(v float64) {
+	arr.elems = append(arr.elems, formatFloat(v, 64))
+}
+func (arr *logfmtArrayEncoder) AppendFloat32(v float32) {
+	arr.elems = append(arr.elems, formatFloat(float64(v), 32))
+}
+func (arr *logfmtArrayEncoder) AppendInt(v int) { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArrayEncoder) AppendInt64(v int64) {
+	arr.elems = append(arr.elems, strconv.FormatInt(v, 10))
+}
+func (arr *logfmtArrayEncoder) AppendInt32(v int32)   { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArrayEncoder) AppendInt16(v int16)   { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArrayEncoder) AppendInt8(v int8)     { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArrayEncoder) AppendString(v string) { arr.appendElem(v) }
+func (arr *logfmtArrayEncoder) AppendUint(v uint)     { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArrayEncoder) AppendUint64(v uint64) {
+	arr.elems = append(arr.elems, strconv.FormatUint(v, 10))
+}
+func (arr *logfmtArrayEncoder) AppendUint32(v uint32)   { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArrayEncoder) AppendUint16(v uint16)   { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArrayEncoder) AppendUint8(v uint8)     { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArrayEncoder) AppendUintptr(v uintptr) { arr.AppendUint64(uint64(v)) }
+
+func (arr *logfmtArrayEncoder) AppendDuration(v time.Duration) {
+	before := len(arr.elems)
+	if arr.EncoderConfig != nil && arr.EncodeDuration != nil {
+		arr.EncodeDuration(v, arr)
+	}
+	if len(arr.elems) == before {
+		arr.elems = append(arr.elems, v.String())
+	}
+}
+
+func (arr *logfmtArrayEncoder) AppendTime(v time.Time) {
+	before := len(arr.elems)
+	if arr.EncoderConfig != nil && arr.EncodeTime != nil {
+		arr.EncodeTime(v, arr)
+	}
+	if len(arr.elems) == before {
+		arr.elems = append(arr.elems, v.Format(time.RFC3339Nano))
+	}
+}
+
+// appendLogfmtKey 写入键名，空白、=、引号和控制字符替换为下划线
+func appendLogfmtKey(buf *buffer.Buffer, key string) {
+	if key == "" {
+		buf.AppendByte('_')
+		return
+	}
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		if r == utf8.RuneError && size == 1 || r <= ' ' || r == '=' || r == '"' || r == 0x7f || unicode.IsSpace(r) {
+			buf.AppendByte('_')
+		} else {
+			buf.AppendString(key[i : i+size])
+		}
+		i += size
+	}
+}
+
+// appendLogfmtValue 写入值，需要时加引号并转义
+func appendLogfmtValue(buf *buffer.Buffer, value string) {
+	if !needsLogfmtQuoting(value) {
+		buf.AppendString(value)
+		return
+	}
+	appendQuotedLogfmtValue(buf, value)
+}
+
+// appendQuotedLogfmtValue 加引号写入值，转义引号、反斜杠和控制字符，无效 UTF-8 替换为 U+FFFD
+func appendQuotedLogfmtValue(buf *buffer.Buffer, value string) {
+	buf.AppendByte('"')
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf.AppendString("\ufffd")
+		case r == '"':
+			buf.AppendString(`\"`)
+		case r == '\\':
+			buf.AppendString(`\\`)
+		case r == '\n':
+			buf.AppendString(`\n`)
+		case r == '\r':
+			buf.AppendString(`\r`)
+		case r == '\t':
+			buf.AppendString(`\t`)
+		case r < ' ' || r == 0x7f:
+			buf.AppendString(`\u00`)
+			buf.AppendByte(hexDigits[r>>4])
+			buf.AppendByte(hexDigits[r&0xf])
+		default:
+			buf.AppendString(value[i : i+size])
+		}
+		i += size
+	}
+	buf.AppendByte('"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// needsLogfmtQuoting 值为空或包含空白、=、引号、控制字符、无效 UTF-8 时需要加引号
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if r == utf8.RuneError && size == 1 || r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f ||
+			unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func formatFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'f', -1, bitSize)
+}
+
+func formatComplex(value complex128, bitSize int) string {
+	return strconv.FormatComplex(value, 'f', -1, bitSize*2)
+}
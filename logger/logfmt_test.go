@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encodeLogfmt 只编码字段，不输出时间、级别和消息
+func encodeLogfmt(t *testing.T, enc zapcore.Encoder, fields ...zapcore.Field) string {
+	t.Helper()
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestLogfmtQuoting(t *testing.T) {
+	tests := []struct {
+		name  string
+		field zapcore.Field
+		want  string
+	}{
+		{"plain", zap.String("k", "plain"), `k=plain`},
+		{"empty", zap.String("k", ""), `k=""`},
+		{"space", zap.String("k", "a b"), `k="a b"`},
+		{"equals", zap.String("k", "a=b"), `k="a=b"`},
+		{"quote", zap.String("k", `say "hi"`), `k="say \"hi\""`},
+		{"backslash", zap.String("k", `C:\logs`), `k="C:\\logs"`},
+		{"newline and tab", zap.String("k", "a\nb\tc\rd"), `k="a\nb\tc\rd"`},
+		{"control", zap.String("k", "\x00\x1b\x7f"), `k="\u0000\u001b\u007f"`},
+		{"invalid utf8", zap.String("k", "bad\xffutf8"), "k=\"bad\ufffdutf8\""},
+		{"unicode space", zap.String("k", "a\u00a0b"), "k=\"a\u00a0b\""},
+		{"chinese", zap.String("k", "中文日志"), `k=中文日志`},
+		{"comma at top level", zap.String("k", "x,y"), `k=x,y`},
+		{"key with space", zap.String("a b", "v"), `a_b=v`},
+		{"empty key", zap.String("", "v"), `_=v`},
+		{"key with equals and quote", zap.String(`k="x"`, "v"), `k__x_=v`},
+		{"nan", zap.Float64("f", math.NaN()), `f=NaN`},
+		{"inf", zap.Float64("f", math.Inf(-1)), `f=-Inf`},
+		{"float", zap.Float64("f", 1.5), `f=1.5`},
+		{"bool", zap.Bool("b", true), `b=true`},
+		{"ints", zap.Ints("n", []int{1, 2}), `n=[1,2]`},
+		{"strings", zap.Strings("l", []string{"a", "b c", "x,y"}), `l="[a,\"b c\",\"x,y\"]"`},
+		{"object", zap.Object("o", testObject{Name: "a b", Count: 2}), `o.name="a b" o.count=2`},
+		{"reflected", zap.Any("m", map[string]int{"x": 1}), `m="{\"x\":1}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encodeLogfmt(t, NewLogfmtEncoder(zapcore.EncoderConfig{}), tt.field)
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogfmtObjectsInArray(t *testing.T) {
+	objects := zap.Array("objs", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, name := range []string{"x,y", "a}b", "plain"} {
+			if err := arr.AppendObject(testObject{Name: name, Count: 1}); err != nil {
+				return err
+			}
+		}
+		return arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("k,{}", "v")
+			return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				arr.AppendString("p,q")
+				arr.AppendInt(1)
+				return nil
+			}))
+		}))
+	}))
+
+	got := encodeLogfmt(t, NewLogfmtEncoder(zapcore.EncoderConfig{}), objects)
+	// 对象中的值包含分隔符时加引号，键名中的分隔符替换为下划线，数组字段原样嵌套
+	inner := `[{name="x,y",count=1},{name="a}b",count=1},{name=plain,count=1},{k___=v,tags=["p,q",1]}]`
+	want := `objs="` + strings.NewReplacer(`"`, `\"`).Replace(inner) + `"`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestLogfmtNamespace(t *testing.T) {
+	got := encodeLogfmt(t, NewLogfmtEncoder(zapcore.EncoderConfig{}),
+		zap.String("before", "a"),
+		zap.Namespace("ns"),
+		zap.String("k", "v"),
+		zap.Object("o", testObject{Name: "n", Count: 1}),
+	)
+	want := `before=a ns.k=v ns.o.name=n ns.o.count=1`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestLogfmtEntry(t *testing.T) {
+	enc := NewLogfmtEncoder(zapcore.EncoderConfig{
+		TimeKey:     "ts",
+		LevelKey:    "level",
+		NameKey:     "logger",
+		MessageKey:  "msg",
+		EncodeTime:  zapcore.ISO8601TimeEncoder,
+		EncodeLevel: zapcore.LowercaseLevelEncoder,
+	})
+	// With 添加的字段保存在克隆的编码器中，输出在调用时的字段之前
+	enc.AddString("user", "alice")
+	clone := enc.Clone()
+	clone.AddInt("attempt", 2)
+
+	entry := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC),
+		LoggerName: "api",
+		Message:    "请求 失败",
+	}
+	buf, err := clone.EncodeEntry(entry, []zapcore.Field{zap.String("path", "/v1/items?id=1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+
+	want := `ts=2026-10-16T08:30:00.000Z level=warn logger=api msg="请求 失败" user=alice attempt=2 path="/v1/items?id=1"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -1,68 +1,50 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger 包装的日志器
 type Logger struct {
-	zapLogger *zap.Logger
-	config    *Config
+	zapLogger   *zap.Logger
+	config      *Config
+	atomicLevel zap.AtomicLevel // 所有 core 共享的动态日志级别
+	closers     []*fileWriter   // 文件输出的写入器，关闭日志器时需要关闭
+	state       *loggerState    // 与 With 派生的子日志器共享
 }
 
-// New 创建新的日志实例，并设置为全局日志器
-func New(options ...Option) (*Logger, error) {
-	config := &Config{
-		Level:         DefaultLevel,
-		Encoding:      DefaultEncoding,
-		MaxSize:       DefaultMaxSize,
-		MaxBackups:    DefaultMaxBackups,
-		MaxAge:        DefaultMaxAge,
-		Compress:      DefaultCompress,
-		ShowCaller:    DefaultShowCaller,
-		Stacktrace:    DefaultStacktrace,
-		ConsoleOutput: DefaultConsoleOutput,
-	}
-
-	for _, opt := range options {
-		opt(config)
-	}
-
-	// 如果设置了BasePath且未设置OutputPath，则自动设置OutputPath
-	if config.BasePath != "" && config.OutputPath == "" && config.OutputPath != "stdout" {
-		config.OutputPath = filepath.Join(config.BasePath, "app.log")
-	}
+// loggerState 日志器及其子日志器共享的运行状态
+type loggerState struct {
+	closed     atomic.Bool
+	async      *asyncPipeline // 异步写入管道，同步模式为 nil
+	quota      *diskQuota     // 文件输出的磁盘用量统计及配额
+	stopReopen func()         // 停止监听 SIGHUP，仅 external 轮转模式
+}
 
-	// 如果设置了BasePath且未设置ErrorPath，则自动设置ErrorPath
-	if config.BasePath != "" && config.ErrorPath == "" {
-		config.ErrorPath = filepath.Join(config.BasePath, "error.log")
+// New 创建新的日志实例，默认同时设置为全局日志器（包括 zap.L()），可通过 WithSetGlobal(false) 关闭
+func New(options ...Option) (*Logger, error) {
+	config, err := buildConfig(options...)
+	if err != nil {
+		return nil, err
 	}
+	return newLogger(config)
+}
 
-	// 如果设置了BasePath且未设置各级别路径，则自动生成各级别路径
-	if config.BasePath != "" {
-		if config.DebugPath == "" {
-			config.DebugPath = filepath.Join(config.BasePath, "debug.log")
-		}
-		if config.InfoPath == "" {
-			config.InfoPath = filepath.Join(config.BasePath, "info.log")
-		}
-		if config.WarnPath == "" {
-			config.WarnPath = filepath.Join(config.BasePath, "warn.log")
-		}
-		if config.ErrorLPath == "" {
-			config.ErrorLPath = filepath.Join(config.BasePath, "error_l.log")
-		}
-		if config.PanicPath == "" {
-			config.PanicPath = filepath.Join(config.BasePath, "panic.log")
-		}
-		if config.FatalPath == "" {
-			config.FatalPath = filepath.Join(config.BasePath, "fatal.log")
+// newLogger 根据完整的配置创建日志实例
+func newLogger(config *Config) (*Logger, error) {
+	// 严格模式下配置有任何问题都直接返回错误，而不是静默降级
+	if config.Strict {
+		if err := config.Validate(); err != nil {
+			return nil, err
 		}
 	}
 
@@ -74,7 +56,8 @@ func New(options ...Option) (*Logger, error) {
 	if err != nil {
 		return nil, err
 	}
-	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	atomicLevel := zap.NewAtomicLevelAt(level)
+	zapConfig.Level = atomicLevel
 
 	// 设置编码格式
 	zapConfig.Encoding = string(config.Encoding)
@@ -99,65 +82,63 @@ func New(options ...Option) (*Logger, error) {
 	encoderConfig.FunctionKey = zapcore.OmitKey
 	encoderConfig.MessageKey = "msg"
 	encoderConfig.StacktraceKey = "stacktrace"
-	encoder := getEncoder(encoderConfig, config.Encoding)
+	// 应用用户的编码器配置，无效的部分保持默认
+	baseEncoderConfig := encoderConfig
+	if err := config.Encoder.apply(&encoderConfig); err != nil {
+		fmt.Printf("WARN: Invalid encoder config: %v. Using defaults for invalid settings.\n", err)
+	}
+	for _, err := range config.encodingErrors() {
+		fmt.Printf("WARN: %v. Falling back to JSON encoding.\n", err)
+	}
+	// 控制台和文件可以使用不同的编码格式
+	encoder := getEncoder(encoderConfig, config.fileEncoding())
+	if config.usesSchema(config.fileEncoding()) {
+		encoder = newSchemaEncoder(baseEncoderConfig, config)
+	}
 
 	// 创建 core
 	cores := []zapcore.Core{}
 
 	// 控制台输出
 	if (config.OutputPath == "" || config.OutputPath == "stdout") || config.ConsoleOutput {
+		// 控制台和文件各用各的编码器，颜色只用于终端
+		consoleEncoder := getEncoder(encoderConfig, config.consoleEncoding())
+		if useColor(config) {
+			consoleEncoder = newColorConsoleEncoder(encoderConfig, config.Encoder.LevelStyle)
+		} else if config.usesSchema(config.consoleEncoding()) {
+			consoleEncoder = newSchemaEncoder(baseEncoderConfig, config)
+		}
 		consoleCore := zapcore.NewCore(
-			encoder,
-			zapcore.Lock(os.Stdout),
-			zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-				return lvl >= level
-			}),
+			consoleEncoder,
+			zapcore.Lock(stdWriteSyncer{os.Stdout}),
+			atomicLevel,
 		)
-		cores = append(cores, consoleCore)
-	}
-
-	// 文件输出 - 捕获该级别及以上的所有日志
-	if config.OutputPath != "" && config.OutputPath != "stdout" {
-		fileCore := createFileCore(config.OutputPath, encoder, level, config, false)
-		cores = append(cores, fileCore)
-	}
-
-	// 错误日志单独输出（兼容旧配置） - 捕获Error及以上级别日志
-	if config.ErrorPath != "" {
-		errorLevel := zap.ErrorLevel
-		errorCore := createFileCore(config.ErrorPath, encoder, errorLevel, config, false)
-		cores = append(cores, errorCore)
-	}
-
-	// 为各个级别单独创建日志文件输出 - 只捕获特定级别的日志
-	if config.DebugPath != "" {
-		debugCore := createFileCore(config.DebugPath, encoder, zap.DebugLevel, config, true)
-		cores = append(cores, debugCore)
-	}
-
-	if config.InfoPath != "" {
-		infoCore := createFileCore(config.InfoPath, encoder, zap.InfoLevel, config, true)
-		cores = append(cores, infoCore)
-	}
-
-	if config.WarnPath != "" {
-		warnCore := createFileCore(config.WarnPath, encoder, zap.WarnLevel, config, true)
-		cores = append(cores, warnCore)
-	}
-
-	if config.ErrorLPath != "" {
-		errorLCore := createFileCore(config.ErrorLPath, encoder, zap.ErrorLevel, config, true)
-		cores = append(cores, errorLCore)
-	}
-
-	if config.PanicPath != "" {
-		panicCore := createFileCore(config.PanicPath, encoder, zap.PanicLevel, config, true)
-		cores = append(cores, panicCore)
-	}
-
-	if config.FatalPath != "" {
-		fatalCore := createFileCore(config.FatalPath, encoder, zap.FatalLevel, config, true)
-		cores = append(cores, fatalCore)
+		cores = append(cores, wrapSchemaCore(consoleCore, config.consoleEncoding(), config))
+	}
+
+	// 文件输出
+	var closers []*fileWriter
+	quota := newDiskQuota(config.DiskQuota)
+	for _, output := range config.fileOutputs() {
+		// 路径中还有无法展开的占位符（例如未设置 BasePath 时的 {base}）时不创建文件，避免写入 ./{base}/ 这样的目录
+		if err := config.checkPlaceholders(output.name, output.path); err != nil {
+			if config.Strict {
+				closeAll(context.Background(), closers)
+				return nil, err
+			}
+			fmt.Printf("WARN: %v. Falling back to console output.\n", err)
+			cores = append(cores, wrapSchemaCore(consoleFallbackCore(encoder, output.level, atomicLevel, output.isLevelSpecific), config.fileEncoding(), config))
+			continue
+		}
+		fileCore, closer, err := createFileCore(output.path, encoder, output.level, atomicLevel, config, output.isLevelSpecific, quota)
+		if err != nil {
+			closeAll(context.Background(), closers)
+			return nil, err
+		}
+		cores = append(cores, wrapSchemaCore(fileCore, config.fileEncoding(), config))
+		if closer != nil {
+			closers = append(closers, closer)
+		}
 	}
 
 	// 创建 logger
@@ -176,27 +157,35 @@ func New(options ...Option) (*Logger, error) {
 		opts = append(opts, zap.AddStacktrace(zap.PanicLevel))
 	}
 
-	// 创建基础logger
-	baseLogger := zap.New(core, opts...)
-
-	var zapLogger *zap.Logger
+	// 异步模式：日志在调用时确定时间和调用者，由该日志器独占的写入协程按顺序写入
+	state := &loggerState{quota: quota}
 	if config.AsyncMode {
-		// 保留对基础logger的引用，用于异步日志处理
-		zapLogger = baseLogger
-	} else {
-		// 同步模式直接使用基础logger
-		zapLogger = baseLogger
+		state.async = newAsyncPipeline(core, config)
+		core = &asyncCore{Core: core, pipeline: state.async}
 	}
 
+	// 创建基础logger
+	zapLogger := zap.New(core, opts...)
+
 	logger := &Logger{
-		zapLogger: zapLogger,
-		config:    config,
+		zapLogger:   zapLogger,
+		config:      config,
+		atomicLevel: atomicLevel,
+		closers:     closers,
+		state:       state,
+	}
+
+	// external 轮转模式：收到 SIGHUP 时重新打开日志文件
+	if config.Rotation == RotateExternal && len(closers) > 0 {
+		state.stopReopen = watchReopenSignal(logger)
 	}
 
 	// 设置为全局日志器
-	globalMutex.Lock()
-	globalLogger = logger
-	globalMutex.Unlock()
+	if !config.skipGlobal {
+		globalMutex.Lock()
+		setGlobal(logger)
+		globalMutex.Unlock()
+	}
 
 	return logger, nil
 }
@@ -207,60 +196,73 @@ func NewDefault() (*Logger, error) {
 }
 
 // 在 createFileCore 函数中添加更好的错误处理
-func createFileCore(filePath string, encoder zapcore.Encoder, level zapcore.Level, config *Config, isLevelSpecific bool) zapcore.Core {
+// 返回的 *fileWriter 用于关闭文件，回退到控制台输出时为 nil
+func createFileCore(filePath string, encoder zapcore.Encoder, level zapcore.Level, atomicLevel zap.AtomicLevel, config *Config, isLevelSpecific bool, quota *diskQuota) (zapcore.Core, *fileWriter, error) {
+	writer, err := newFileWriter(filePath, config)
+	if err != nil {
+		if config.Strict {
+			return nil, nil, err
+		}
+		// 时区无效时使用本地时区计算轮转周期
+		fmt.Printf("WARN: %v. Falling back to local time zone.\n", err)
+		fallback := *config
+		fallback.RotationTimeZone = ""
+		writer, _ = newFileWriter(filePath, &fallback)
+	}
+
 	// 确保目录存在
-	dir := filepath.Dir(filePath)
+	dir := writer.dir(time.Now())
 	if err := os.MkdirAll(dir, 0755); err != nil {
+		// 严格模式下直接返回错误
+		if config.Strict {
+			return nil, nil, fmt.Errorf("create log directory %s: %w", dir, err)
+		}
 		// 如果创建目录失败，回退到控制台输出并记录警告
 		fmt.Printf("WARN: Failed to create log directory %s: %v. Falling back to console output.\n", dir, err)
-		return zapcore.NewCore(
-			encoder,
-			zapcore.Lock(os.Stdout),
-			zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-				if isLevelSpecific {
-					// 只捕获特定级别的日志
-					return lvl == level
-				}
-				// 捕获该级别及以上的所有日志
-				return lvl >= level
-			}),
-		)
-	}
-
-	lumberJackLogger := &lumberjack.Logger{
-		Filename:   filePath,
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+		return consoleFallbackCore(encoder, level, atomicLevel, isLevelSpecific), nil, nil
 	}
 
-	writer := zapcore.AddSync(lumberJackLogger)
+	quota.register(writer)
 
 	return zapcore.NewCore(
 		encoder,
 		writer,
-		zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			if isLevelSpecific {
-				// 只捕获特定级别的日志
-				return lvl == level
-			}
-			// 捕获该级别及以上的所有日志
-			return lvl >= level
-		}),
+		fileLevelEnabler(level, atomicLevel, isLevelSpecific),
+	), writer, nil
+}
+
+// consoleFallbackCore 文件无法使用时代替文件 core 输出到控制台，级别过滤与文件 core 相同
+func consoleFallbackCore(encoder zapcore.Encoder, level zapcore.Level, atomicLevel zap.AtomicLevel, isLevelSpecific bool) zapcore.Core {
+	return zapcore.NewCore(
+		encoder,
+		zapcore.Lock(stdWriteSyncer{os.Stdout}),
+		fileLevelEnabler(level, atomicLevel, isLevelSpecific),
 	)
 }
 
+// fileLevelEnabler 文件 core 的级别过滤器，同时受文件自身级别和日志器动态级别约束
+func fileLevelEnabler(level zapcore.Level, atomicLevel zap.AtomicLevel, isLevelSpecific bool) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		if !atomicLevel.Enabled(lvl) {
+			return false
+		}
+		if isLevelSpecific {
+			// 只捕获特定级别的日志
+			return lvl == level
+		}
+		// 捕获该级别及以上的所有日志
+		return lvl >= level
+	})
+}
+
 // getEncoder 获取编码器
 func getEncoder(encoderConfig zapcore.EncoderConfig, encoding Encoding) zapcore.Encoder {
 	// 确保编码器配置支持UTF-8字符
 	switch encoding {
 	case ConsoleEncoding:
-		// 为控制台编码器配置更好的UTF-8支持
-		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-		encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
 		return zapcore.NewConsoleEncoder(encoderConfig)
+	case LogfmtEncoding:
+		return NewLogfmtEncoder(encoderConfig)
 	case JSONEncoding:
 		fallthrough
 	default:
@@ -288,25 +290,6 @@ func parseLevel(level Level) (zapcore.Level, error) {
 	}
 }
 
-// 异步日志相关变量
-var (
-	// 用于异步日志处理的工作池
-	logWorkers = 4
-	// 日志任务队列
-	logChan = make(chan func(), 1000)
-)
-
-func init() {
-	// 启动异步日志工作协程
-	for i := 0; i < logWorkers; i++ {
-		go func() {
-			for task := range logChan {
-				task()
-			}
-		}()
-	}
-}
-
 // 基础日志方法
 func (l *Logger) Debug(msg string, fields ...zap.Field) {
 	if l == nil || l.zapLogger == nil {
@@ -314,25 +297,7 @@ func (l *Logger) Debug(msg string, fields ...zap.Field) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Debug(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Debug(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Debug(msg, fields...)
-	}
+	l.zapLogger.Debug(msg, fields...)
 }
 
 func (l *Logger) Info(msg string, fields ...zap.Field) {
@@ -341,25 +306,7 @@ func (l *Logger) Info(msg string, fields ...zap.Field) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Info(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Info(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Info(msg, fields...)
-	}
+	l.zapLogger.Info(msg, fields...)
 }
 
 func (l *Logger) Warn(msg string, fields ...zap.Field) {
@@ -368,25 +315,7 @@ func (l *Logger) Warn(msg string, fields ...zap.Field) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Warn(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Warn(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Warn(msg, fields...)
-	}
+	l.zapLogger.Warn(msg, fields...)
 }
 
 func (l *Logger) Error(msg string, fields ...zap.Field) {
@@ -395,25 +324,7 @@ func (l *Logger) Error(msg string, fields ...zap.Field) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Error(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Error(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Error(msg, fields...)
-	}
+	l.zapLogger.Error(msg, fields...)
 }
 
 func (l *Logger) Panic(msg string, fields ...zap.Field) {
@@ -421,26 +332,7 @@ func (l *Logger) Panic(msg string, fields ...zap.Field) {
 		panic(fmt.Sprintf("PANIC: %s", msg))
 	}
 
-	// Panic级别的日志通常需要立即执行，不建议异步处理
-	// 但我们仍然提供异步选项，同时保留原始行为
-	if l.config.AsyncMode {
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Panic(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行
-			l.zapLogger.Panic(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Panic(msg, fields...)
-	}
+	l.zapLogger.Panic(msg, fields...)
 }
 
 func (l *Logger) Fatal(msg string, fields ...zap.Field) {
@@ -449,26 +341,7 @@ func (l *Logger) Fatal(msg string, fields ...zap.Field) {
 		os.Exit(1)
 	}
 
-	// Fatal级别的日志通常需要立即执行，不建议异步处理
-	// 但我们仍然提供异步选项，同时保留原始行为
-	if l.config.AsyncMode {
-		logMsg := msg
-		logFields := make([]zap.Field, len(fields))
-		copy(logFields, fields)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Fatal(logMsg, logFields...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行
-			l.zapLogger.Fatal(msg, fields...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Fatal(msg, fields...)
-	}
+	l.zapLogger.Fatal(msg, fields...)
 }
 
 // 格式化日志方法
@@ -478,25 +351,7 @@ func (l *Logger) Debugf(format string, args ...interface{}) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Debugf(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Sugar().Debugf(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Debugf(format, args...)
-	}
+	l.zapLogger.Sugar().Debugf(format, args...)
 }
 
 func (l *Logger) Infof(format string, args ...interface{}) {
@@ -505,25 +360,7 @@ func (l *Logger) Infof(format string, args ...interface{}) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Infof(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Sugar().Infof(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Infof(format, args...)
-	}
+	l.zapLogger.Sugar().Infof(format, args...)
 }
 
 func (l *Logger) Warnf(format string, args ...interface{}) {
@@ -532,25 +369,7 @@ func (l *Logger) Warnf(format string, args ...interface{}) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Warnf(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Sugar().Warnf(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Warnf(format, args...)
-	}
+	l.zapLogger.Sugar().Warnf(format, args...)
 }
 
 func (l *Logger) Errorf(format string, args ...interface{}) {
@@ -559,25 +378,7 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 		return
 	}
 
-	if l.config.AsyncMode {
-		// 异步模式：将日志写入任务发送到通道
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Errorf(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行以避免日志丢失
-			l.zapLogger.Sugar().Errorf(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Errorf(format, args...)
-	}
+	l.zapLogger.Sugar().Errorf(format, args...)
 }
 
 func (l *Logger) Panicf(format string, args ...interface{}) {
@@ -585,25 +386,7 @@ func (l *Logger) Panicf(format string, args ...interface{}) {
 		panic(fmt.Sprintf(format, args...))
 	}
 
-	// Panic级别的日志通常需要立即执行，不建议异步处理
-	if l.config.AsyncMode {
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Panicf(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行
-			l.zapLogger.Sugar().Panicf(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Panicf(format, args...)
-	}
+	l.zapLogger.Sugar().Panicf(format, args...)
 }
 
 func (l *Logger) Fatalf(format string, args ...interface{}) {
@@ -612,33 +395,39 @@ func (l *Logger) Fatalf(format string, args ...interface{}) {
 		os.Exit(1)
 	}
 
-	// Fatal级别的日志通常需要立即执行，不建议异步处理
-	if l.config.AsyncMode {
-		logFormat := format
-		logArgs := make([]interface{}, len(args))
-		copy(logArgs, args)
-
-		select {
-		case logChan <- func() {
-			l.zapLogger.Sugar().Fatalf(logFormat, logArgs...)
-		}:
-			// 任务已发送到通道
-		default:
-			// 通道已满，同步执行
-			l.zapLogger.Sugar().Fatalf(format, args...)
-		}
-	} else {
-		// 同步模式：直接写入日志
-		l.zapLogger.Sugar().Fatalf(format, args...)
-	}
+	l.zapLogger.Sugar().Fatalf(format, args...)
 }
 
 // With 添加字段
 func (l *Logger) With(fields ...zap.Field) *Logger {
 	return &Logger{
-		zapLogger: l.zapLogger.With(fields...),
-		config:    l.config,
+		zapLogger:   l.zapLogger.With(fields...),
+		config:      l.config,
+		atomicLevel: l.atomicLevel,
+		closers:     l.closers,
+		state:       l.state,
+	}
+}
+
+// SetLevel 动态调整日志级别，对该日志器创建的所有 core（包括各级别文件）立即生效，可在记录日志的同时并发调用
+func (l *Logger) SetLevel(level Level) error {
+	if l == nil || l.atomicLevel == (zap.AtomicLevel{}) {
+		return fmt.Errorf("logger does not support level changes")
+	}
+	zapLevel, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.atomicLevel.SetLevel(zapLevel)
+	return nil
+}
+
+// Level 获取当前生效的日志级别
+func (l *Logger) Level() Level {
+	if l == nil || l.atomicLevel == (zap.AtomicLevel{}) {
+		return DebugLevel
 	}
+	return Level(l.atomicLevel.Level().String())
 }
 
 // Sync 将缓冲区刷新到磁盘
@@ -648,20 +437,94 @@ func (l *Logger) Sync() error {
 	}
 	// 尝试sync，但优雅地处理错误
 	// 特别是当输出到stdout时，sync操作会失败，这是正常的
-	if err := l.zapLogger.Sync(); err != nil {
-		// 检查是否是因为stdout导致的错误
-		if err.Error() == "sync /dev/stdout: inappropriate ioctl for device" ||
-			err.Error() == "sync /dev/stderr: inappropriate ioctl for device" {
-			// 这些错误是预期的，我们可以忽略它们
-			return nil
-		}
-		// 对于其他错误，仍然返回
-		return err
+	return ignoreStdSyncError(l.zapLogger.Sync())
+}
+
+// ignoreStdSyncError 忽略同步标准输出/标准错误时的预期错误
+// 标准输出是终端、管道或 /dev/null（容器、systemd、CI 中很常见）时 fsync 会返回 ENOTTY 或 EINVAL，这不是写入失败
+func ignoreStdSyncError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) &&
+		(pathErr.Path == os.Stdout.Name() || pathErr.Path == os.Stderr.Name()) &&
+		(errors.Is(pathErr.Err, syscall.EINVAL) || errors.Is(pathErr.Err, syscall.ENOTTY)) {
+		return nil
 	}
-	return nil
+	return err
+}
+
+// stdWriteSyncer 包装标准输出/标准错误，Sync 时忽略无法 fsync 的预期错误
+type stdWriteSyncer struct {
+	*os.File
+}
+
+// Sync 实现 zapcore.WriteSyncer
+func (s stdWriteSyncer) Sync() error {
+	return ignoreStdSyncError(s.File.Sync())
 }
 
 // GetZapLogger 获取原始的 zap logger（用于高级用法）
 func (l *Logger) GetZapLogger() *zap.Logger {
 	return l.zapLogger
 }
+
+// Close 关闭日志器：在 ctx 截止前等待尚未写入的异步日志，然后同步并关闭所有日志文件
+// 关闭后继续记录的日志会同步写入，原本写文件的日志改为写到标准错误输出；重复调用不会出错
+func (l *Logger) Close(ctx context.Context) error {
+	if l == nil || l.state == nil {
+		return nil
+	}
+	if !l.state.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if l.state.stopReopen != nil {
+		l.state.stopReopen()
+	}
+
+	var waitErr, syncErr error
+	if l.state.async != nil {
+		waitErr = l.state.async.close(ctx)
+	}
+	if waitErr != nil {
+		// 已超时：不再等待队列（Sync 会一直等到队列写完），只刷新底层 core
+		syncErr = ignoreStdSyncError(l.state.async.root.Sync())
+	} else {
+		syncErr = l.Sync()
+	}
+	closeErr := l.closeWriters(ctx)
+	return errors.Join(waitErr, syncErr, closeErr)
+}
+
+// DroppedEntries 返回异步队列满时按溢出策略丢弃的日志总数
+func (l *Logger) DroppedEntries() uint64 {
+	if l == nil || l.state == nil || l.state.async == nil {
+		return 0
+	}
+	return l.state.async.dropped.Load()
+}
+
+// DiskUsage 返回所有日志文件（含轮转产生的旧文件）当前占用的磁盘空间（字节）
+func (l *Logger) DiskUsage() int64 {
+	if l == nil || l.state == nil || l.state.quota == nil {
+		return 0
+	}
+	return l.state.quota.usage()
+}
+
+// closeWriters 关闭该日志器打开的所有日志文件，在 ctx 截止前等待后台的压缩和轮转回调
+func (l *Logger) closeWriters(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return closeAll(ctx, l.closers)
+}
+
+// closeAll 依次关闭，返回所有错误
+func closeAll(ctx context.Context, closers []*fileWriter) error {
+	var errs []error
+	for _, closer := range closers {
+		if err := closer.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
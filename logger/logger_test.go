@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestIgnoreStdSyncError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		ignored bool
+	}{
+		{"nil", nil, true},
+		{"stdout tty", &os.PathError{Op: "sync", Path: os.Stdout.Name(), Err: syscall.ENOTTY}, true},
+		{"stdout pipe", &os.PathError{Op: "sync", Path: os.Stdout.Name(), Err: syscall.EINVAL}, true},
+		{"stderr pipe", &os.PathError{Op: "sync", Path: os.Stderr.Name(), Err: syscall.EINVAL}, true},
+		{"stdout io error", &os.PathError{Op: "sync", Path: os.Stdout.Name(), Err: syscall.EIO}, false},
+		{"log file", &os.PathError{Op: "sync", Path: "/var/log/app.log", Err: syscall.EINVAL}, false},
+		{"other", errors.New("sync /dev/stdout: invalid argument"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ignoreStdSyncError(tt.err); (got == nil) != tt.ignored {
+				t.Errorf("ignoreStdSyncError(%v) = %v", tt.err, got)
+			}
+		})
+	}
+}
+
+// readMessages 返回 JSON 日志文件中每一行的 msg，文件不存在时返回空
+func readMessages(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry struct {
+			Msg string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		messages = append(messages, entry.Msg)
+	}
+	return messages
+}
+
+// SetLevel 同时作用于主日志文件、error 文件和各级别文件
+func TestSetLevelAppliesToEveryFile(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(WithSetGlobal(false), WithBasePath(dir), WithLevel(InfoLevel), WithConsoleOutput(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("d1")
+	l.Info("i1")
+	l.Warn("w1")
+	l.Error("e1")
+	if err := l.SetLevel(DebugLevel); err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("d2")
+	l.Info("i2")
+	if err := l.SetLevel(ErrorLevel); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("i3")
+	l.Warn("w3")
+	l.Error("e3")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"app.log":     {"i1", "w1", "e1", "d2", "i2", "e3"},
+		"error.log":   {"e1", "e3"},
+		"debug.log":   {"d2"},
+		"info.log":    {"i1", "i2"},
+		"warn.log":    {"w1"},
+		"error_l.log": {"e1", "e3"},
+		"panic.log":   nil,
+		"fatal.log":   nil,
+	}
+	for name, messages := range want {
+		if got := readMessages(t, filepath.Join(dir, name)); !reflect.DeepEqual(got, messages) {
+			t.Errorf("%s = %v, want %v", name, got, messages)
+		}
+	}
+}
+
+// 控制台和文件各自使用自己的编码格式，Schema 只作用于 JSON 输出
+func TestOutputsUseTheirOwnEncoding(t *testing.T) {
+	isJSON := func(line string) bool { return json.Valid([]byte(line)) }
+	isLogfmt := func(line string) bool {
+		return strings.HasPrefix(line, "time=") && strings.Contains(line, " msg=hello")
+	}
+	isConsole := func(line string) bool {
+		return strings.Contains(line, "\tINFO\t") && strings.Contains(line, "\thello\t")
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		console func(string) bool
+		file    func(string) bool
+		ecs     bool // 文件输出为 ECS 字段
+	}{
+		{
+			name:    "console override",
+			options: []Option{WithEncoding(JSONEncoding), WithConsoleEncoding(ConsoleEncoding)},
+			console: isConsole,
+			file:    isJSON,
+		},
+		{
+			name:    "file override",
+			options: []Option{WithEncoding(ConsoleEncoding), WithFileEncoding(LogfmtEncoding)},
+			console: isConsole,
+			file:    isLogfmt,
+		},
+		{
+			name:    "schema on file only",
+			options: []Option{WithConsoleEncoding(LogfmtEncoding), WithFileEncoding(JSONEncoding), WithSchema(ECS)},
+			console: isLogfmt,
+			file:    isJSON,
+			ecs:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			stdout := captureStdout(t)
+			options := append([]Option{WithSetGlobal(false), WithOutputPath(path), WithConsoleOutput(true)}, tt.options...)
+			l, err := New(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Info("hello", zap.String("user", "alice"))
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			consoleLine, fileLine := strings.TrimSpace(stdout()), strings.TrimSpace(string(data))
+			if !tt.console(consoleLine) {
+				t.Errorf("console output has the wrong encoding: %q", consoleLine)
+			}
+			if !tt.file(fileLine) {
+				t.Errorf("file output has the wrong encoding: %q", fileLine)
+			}
+			if got := strings.Contains(fileLine, `"ecs.version"`); got != tt.ecs {
+				t.Errorf("file output uses ECS = %v, want %v: %q", got, tt.ecs, fileLine)
+			}
+			if strings.Contains(consoleLine, "ecs.version") {
+				t.Errorf("console output uses ECS: %q", consoleLine)
+			}
+		})
+	}
+}
@@ -3,14 +3,25 @@ package logger
 // Option 配置选项
 type Option func(*Config)
 
+// RotateFunc 文件轮转后的回调，oldPath 为轮转完成的文件（启用压缩时为压缩后的文件），newPath 为新的当前文件
+// 回调在后台协程中执行，返回的错误和 panic 会输出到标准错误
+type RotateFunc func(oldPath, newPath string) error
+
 // Config 日志配置
 type Config struct {
 	Level      Level    `json:"level" yaml:"level"`
 	Encoding   Encoding `json:"encoding" yaml:"encoding"`
+	// 控制台和文件各自的编码格式，为空时使用 Encoding
+	ConsoleEncoding Encoding `json:"console_encoding" yaml:"console_encoding"`
+	FileEncoding    Encoding `json:"file_encoding" yaml:"file_encoding"`
 	OutputPath string   `json:"output_path" yaml:"output_path"`
 	ErrorPath  string   `json:"error_path" yaml:"error_path"`
 	// 基础日志路径，如果设置了此路径且未明确指定各个级别路径，将自动生成各级别日志文件
 	BasePath string `json:"base_path" yaml:"base_path"`
+	// BasePath 下各日志文件的命名模板，默认为 "{base}/{level}.log"，可使用的占位符见 pattern.go
+	FilePattern string `json:"file_pattern" yaml:"file_pattern"`
+	// 应用名称，用于 {app} 占位符，未设置时为可执行文件名
+	AppName string `json:"app_name" yaml:"app_name"`
 	// 各个级别的日志文件路径
 	DebugPath string `json:"debug_path" yaml:"debug_path"`
 	InfoPath  string `json:"info_path" yaml:"info_path"`
@@ -24,13 +35,58 @@ type Config struct {
 	MaxBackups int  `json:"max_backups" yaml:"max_backups"`
 	MaxAge     int  `json:"max_age" yaml:"max_age"`
 	Compress   bool `json:"compress" yaml:"compress"`
+	// 压缩方式（none/gzip/zstd）及压缩级别（gzip 为 1-9，zstd 为 1-22，0 表示默认级别）
+	// 未设置 Compression 时沿用 Compress：true 为 gzip，false 为不压缩
+	Compression      Compression `json:"compression" yaml:"compression"`
+	CompressionLevel int         `json:"compression_level" yaml:"compression_level"`
+	// 轮转方式（size/time/size+time）、时间轮转周期（daily/hourly）及计算周期使用的时区（为空时使用本地时区）
+	Rotation         RotationMode     `json:"rotation" yaml:"rotation"`
+	RotationInterval RotationInterval `json:"rotation_interval" yaml:"rotation_interval"`
+	RotationTimeZone string           `json:"rotation_time_zone" yaml:"rotation_time_zone"`
+	// 所有日志文件（含轮转产生的旧文件）合计占用的磁盘空间上限（字节），0 表示不限制
+	DiskQuota int64 `json:"disk_quota" yaml:"disk_quota"`
+	// 按时间轮转或路径中包含 {date} 时，在不带日期的路径（例如 info.log）上维护指向当前文件的符号链接
+	Symlink bool `json:"symlink" yaml:"symlink"`
+
+	// 编码器配置：各项键名、时间格式及时区、级别/时长/调用者的输出样式
+	Encoder EncoderConfig `json:"encoder" yaml:"encoder"`
+	// JSON 输出使用的字段命名规范（ecs/gcp/otel），为空时使用默认字段名
+	Schema Schema `json:"schema" yaml:"schema"`
+	// 带 context 的日志方法自动添加 OpenTelemetry span 的 trace_id、span_id、trace_flags
+	Trace TraceConfig `json:"trace" yaml:"trace"`
 
 	// 其他配置
 	ShowCaller    bool `json:"show_caller" yaml:"show_caller"`
 	Stacktrace    bool `json:"stacktrace" yaml:"stacktrace"`
 	Development   bool `json:"development" yaml:"development"`
 	AsyncMode     bool `json:"async_mode" yaml:"async_mode"` // 异步日志模式
+	// 异步队列容量及队列满时的处理策略
+	AsyncBufferSize    int            `json:"async_buffer_size" yaml:"async_buffer_size"`
+	AsyncOverflow      OverflowPolicy `json:"async_overflow" yaml:"async_overflow"`
+	AsyncOverflowLevel Level          `json:"async_overflow_level" yaml:"async_overflow_level"` // OverflowDropBelowLevel 时保留的最低级别
 	ConsoleOutput bool `json:"console_output" yaml:"console_output"` // 是否输出到控制台
+	Color         bool `json:"color" yaml:"color"`                   // console 编码输出到终端时着色，设置 NO_COLOR 环境变量时关闭
+	Strict        bool `json:"strict" yaml:"strict"`                 // 严格模式，配置有问题时 New 返回错误而不是降级
+
+	// 应用选项过程中产生的错误，由 New 统一返回
+	errs []error
+	// 不设置为全局日志器
+	skipGlobal bool
+	// 文件轮转后的回调
+	onRotate []RotateFunc
+}
+
+// WithConfig 使用完整的配置，通常与 LoadConfig 配合使用，应放在其他选项之前
+func WithConfig(config *Config) Option {
+	return func(c *Config) {
+		if config != nil {
+			errs, skipGlobal, onRotate := c.errs, c.skipGlobal, c.onRotate
+			*c = *config
+			c.errs = append(errs, config.errs...)
+			c.skipGlobal = skipGlobal
+			c.onRotate = append(onRotate[:len(onRotate):len(onRotate)], config.onRotate...)
+		}
+	}
 }
 
 // WithLevel 设置日志级别
@@ -47,6 +103,119 @@ func WithEncoding(encoding Encoding) Option {
 	}
 }
 
+// WithConsoleEncoding 设置控制台输出的编码格式，未设置时使用 WithEncoding 的值
+func WithConsoleEncoding(encoding Encoding) Option {
+	return func(c *Config) {
+		c.ConsoleEncoding = encoding
+	}
+}
+
+// WithFileEncoding 设置文件输出的编码格式，未设置时使用 WithEncoding 的值
+func WithFileEncoding(encoding Encoding) Option {
+	return func(c *Config) {
+		c.FileEncoding = encoding
+	}
+}
+
+// WithEncoderConfig 设置完整的编码器配置
+func WithEncoderConfig(encoder EncoderConfig) Option {
+	return func(c *Config) {
+		c.Encoder = encoder
+	}
+}
+
+// WithEncoderKeys 设置时间、级别和消息的键名，传入空字符串保持默认，传入 "-" 表示不输出
+func WithEncoderKeys(timeKey, levelKey, messageKey string) Option {
+	return func(c *Config) {
+		c.Encoder.TimeKey = timeKey
+		c.Encoder.LevelKey = levelKey
+		c.Encoder.MessageKey = messageKey
+	}
+}
+
+// WithCallerKey 设置调用者的键名，"-" 表示不输出
+func WithCallerKey(key string) Option {
+	return func(c *Config) {
+		c.Encoder.CallerKey = key
+	}
+}
+
+// WithStacktraceKey 设置堆栈的键名，"-" 表示不输出
+func WithStacktraceKey(key string) Option {
+	return func(c *Config) {
+		c.Encoder.StacktraceKey = key
+	}
+}
+
+// WithNameKey 设置日志器名称的键名，"-" 表示不输出
+func WithNameKey(key string) Option {
+	return func(c *Config) {
+		c.Encoder.NameKey = key
+	}
+}
+
+// WithTimeFormat 设置时间格式及时区
+// layout 可以是 iso8601、rfc3339、rfc3339nano、epoch、epoch_millis、epoch_nanos 或任意 Go 时间格式，zone 为空时使用本地时区
+func WithTimeFormat(layout, zone string) Option {
+	return func(c *Config) {
+		c.Encoder.TimeLayout = layout
+		c.Encoder.TimeZone = zone
+	}
+}
+
+// WithLevelStyle 设置级别的输出样式（capital/lowercase）
+func WithLevelStyle(style LevelStyle) Option {
+	return func(c *Config) {
+		c.Encoder.LevelStyle = style
+	}
+}
+
+// WithDurationStyle 设置时长的输出样式（string/seconds/millis/nanos）
+func WithDurationStyle(style DurationStyle) Option {
+	return func(c *Config) {
+		c.Encoder.DurationStyle = style
+	}
+}
+
+// WithCallerStyle 设置调用者的输出样式（short/full）
+func WithCallerStyle(style CallerStyle) Option {
+	return func(c *Config) {
+		c.Encoder.CallerStyle = style
+	}
+}
+
+// WithSchema 设置 JSON 输出使用的字段命名规范：ECS（Elasticsearch）、GCP（Cloud Logging）或 OTel（OpenTelemetry 日志数据模型）
+// 例如 ECS 把字段改写为 @timestamp、log.level、message 等，zap.Error 等错误字段改写为 error.message 和 error.type
+func WithSchema(schema Schema) Option {
+	return func(c *Config) {
+		c.Schema = schema
+	}
+}
+
+// WithTraceInjection 设置带 context 的日志方法是否自动添加 OpenTelemetry span 的链路追踪字段（默认开启）
+func WithTraceInjection(enable bool) Option {
+	return func(c *Config) {
+		c.Trace.Disabled = !enable
+	}
+}
+
+// WithTraceKeys 设置 trace_id、span_id、trace_flags 的键名，传入空字符串保持默认，传入 "-" 表示不输出
+func WithTraceKeys(traceIDKey, spanIDKey, traceFlagsKey string) Option {
+	return func(c *Config) {
+		c.Trace.TraceIDKey = traceIDKey
+		c.Trace.SpanIDKey = spanIDKey
+		c.Trace.TraceFlagsKey = traceFlagsKey
+	}
+}
+
+// WithTraceFormat 设置 trace_id 和 span_id 的格式（TraceFormatHex/TraceFormatDecimal）及 trace_id 的前缀
+func WithTraceFormat(format TraceFormat, traceIDPrefix string) Option {
+	return func(c *Config) {
+		c.Trace.Format = format
+		c.Trace.TraceIDPrefix = traceIDPrefix
+	}
+}
+
 // WithOutputPath 设置输出路径
 func WithOutputPath(path string) Option {
 	return func(c *Config) {
@@ -113,6 +282,59 @@ func WithFileRotation(maxSize, maxBackups, maxAge int, compress bool) Option {
 	}
 }
 
+// WithCompression 设置轮转后旧文件的压缩方式和压缩级别，level 为 0 时使用默认级别
+func WithCompression(compression Compression, level int) Option {
+	return func(c *Config) {
+		c.Compression = compression
+		c.CompressionLevel = level
+	}
+}
+
+// WithRotation 设置文件轮转方式
+func WithRotation(mode RotationMode) Option {
+	return func(c *Config) {
+		c.Rotation = mode
+	}
+}
+
+// WithRotationInterval 设置按时间轮转的周期
+func WithRotationInterval(interval RotationInterval) Option {
+	return func(c *Config) {
+		c.RotationInterval = interval
+	}
+}
+
+// WithRotationTimeZone 设置计算轮转周期使用的时区，例如 "UTC"、"Asia/Shanghai"
+func WithRotationTimeZone(name string) Option {
+	return func(c *Config) {
+		c.RotationTimeZone = name
+	}
+}
+
+// WithDiskQuota 设置日志器所有日志文件合计占用的磁盘空间上限（字节），超出时从最旧的旧文件开始删除
+func WithDiskQuota(bytes int64) Option {
+	return func(c *Config) {
+		c.DiskQuota = bytes
+	}
+}
+
+// WithOnRotate 添加文件轮转后的回调，可用于上传文件、计算校验和或通知日志采集程序
+// 回调在后台执行，不会阻塞日志写入；多次调用会按顺序执行所有回调；external 模式下不会调用
+func WithOnRotate(fn RotateFunc) Option {
+	return func(c *Config) {
+		if fn != nil {
+			c.onRotate = append(c.onRotate, fn)
+		}
+	}
+}
+
+// WithSymlink 设置是否在不带日期的路径上维护指向当前日志文件的符号链接，便于 tail -F 和日志采集
+func WithSymlink(enable bool) Option {
+	return func(c *Config) {
+		c.Symlink = enable
+	}
+}
+
 // WithCaller 设置是否显示调用者信息
 func WithCaller(show bool) Option {
 	return func(c *Config) {
@@ -141,6 +363,27 @@ func WithAsyncMode(async bool) Option {
 	}
 }
 
+// WithAsyncBufferSize 设置异步模式下每个日志器的队列容量
+func WithAsyncBufferSize(size int) Option {
+	return func(c *Config) {
+		c.AsyncBufferSize = size
+	}
+}
+
+// WithAsyncOverflow 设置异步队列满时的处理策略，被丢弃的日志会计数并定期写一条统计日志
+func WithAsyncOverflow(policy OverflowPolicy) Option {
+	return func(c *Config) {
+		c.AsyncOverflow = policy
+	}
+}
+
+// WithAsyncOverflowLevel 设置 OverflowDropBelowLevel 策略下保留的最低级别（默认 Warn）
+func WithAsyncOverflowLevel(level Level) Option {
+	return func(c *Config) {
+		c.AsyncOverflowLevel = level
+	}
+}
+
 // WithBasePath 设置基础日志路径，系统会自动在该路径下为各个级别生成对应的日志文件
 // 例如：如果设置为"./logs"，则会自动生成"./logs/debug.log", "./logs/info.log"等
 func WithBasePath(path string) Option {
@@ -149,9 +392,48 @@ func WithBasePath(path string) Option {
 	}
 }
 
+// WithFilePattern 设置 BasePath 下各日志文件的命名模板
+// 例如："{base}/{date}/{level}.log" 或 "{base}/{hostname}-{pid}-{level}.log"
+func WithFilePattern(pattern string) Option {
+	return func(c *Config) {
+		c.FilePattern = pattern
+	}
+}
+
+// WithAppName 设置应用名称，用于日志路径中的 {app} 占位符
+func WithAppName(name string) Option {
+	return func(c *Config) {
+		c.AppName = name
+	}
+}
+
 // WithConsoleOutput 设置是否输出日志到控制台
 func WithConsoleOutput(enable bool) Option {
 	return func(c *Config) {
 		c.ConsoleOutput = enable
 	}
 }
+
+// WithColor 设置是否为控制台输出着色：级别按颜色区分，时间和调用者变暗，字段键名高亮
+// 只在 console 编码且标准输出是终端时生效，文件输出和设置了 NO_COLOR 环境变量时不着色
+func WithColor(enable bool) Option {
+	return func(c *Config) {
+		c.Color = enable
+	}
+}
+
+// WithStrict 设置严格模式：New 会先调用 Config.Validate，配置有任何问题（包括无法创建日志目录）都返回错误，
+// 而不是回退到 JSON 编码或控制台输出
+func WithStrict(strict bool) Option {
+	return func(c *Config) {
+		c.Strict = strict
+	}
+}
+
+// WithSetGlobal 设置 New 是否把创建的日志器设置为全局日志器（默认 true）
+// 库和测试创建私有日志器时应传入 false，避免覆盖应用的全局日志器
+func WithSetGlobal(setGlobal bool) Option {
+	return func(c *Config) {
+		c.skipGlobal = !setGlobal
+	}
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 日志路径中可以使用的占位符
+const (
+	PlaceholderBase     = "{base}"     // BasePath
+	PlaceholderApp      = "{app}"      // AppName，未设置时为可执行文件名
+	PlaceholderHostname = "{hostname}" // 主机名
+	PlaceholderPID      = "{pid}"      // 进程号
+	PlaceholderLevel    = "{level}"    // 文件对应的输出：app、error、debug、info、warn、error_l、panic、fatal
+	PlaceholderDate     = "{date}"     // 当前日期（2006-01-02），打开文件和每次轮转时展开
+)
+
+const dateLayout = "2006-01-02"
+
+var placeholderRe = regexp.MustCompile(`\{[^{}/\\]*\}`)
+
+// expandPlaceholders 展开进程内不变的占位符，{date} 保留到打开文件时再展开
+func (c *Config) expandPlaceholders(path, level string) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+	base := c.BasePath
+	if base == "" {
+		// 保留 {base}，由 Validate 报告，New 不会为这样的路径创建文件
+		base = PlaceholderBase
+	}
+	return strings.NewReplacer(
+		PlaceholderBase, base,
+		PlaceholderApp, c.appName(),
+		PlaceholderHostname, hostname(),
+		PlaceholderPID, strconv.Itoa(os.Getpid()),
+		PlaceholderLevel, level,
+	).Replace(path)
+}
+
+// checkPlaceholders 检查路径中的占位符是否都能展开
+func (c *Config) checkPlaceholders(name, path string) error {
+	for _, placeholder := range placeholderRe.FindAllString(path, -1) {
+		switch placeholder {
+		case PlaceholderApp, PlaceholderHostname, PlaceholderPID, PlaceholderLevel, PlaceholderDate:
+		case PlaceholderBase:
+			if c.BasePath == "" {
+				return fmt.Errorf("%s: %s used but base_path is empty", name, placeholder)
+			}
+		default:
+			return fmt.Errorf("%s: unknown placeholder %s", name, placeholder)
+		}
+	}
+	return nil
+}
+
+// appName 应用名称，未设置时使用可执行文件名
+func (c *Config) appName() string {
+	if c.AppName != "" {
+		return c.AppName
+	}
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "localhost"
+	}
+	return name
+}
+
+// expandDate 把 {date} 展开为 t 所在的日期
+func expandDate(path string, t time.Time) string {
+	return strings.ReplaceAll(path, PlaceholderDate, t.Format(dateLayout))
+}
+
+// stripDate 去掉路径中的 {date}，例如 logs/{date}/info.log、logs/info-{date}.log 都得到 logs/info.log
+func stripDate(path string) string {
+	dir, base := filepath.Split(path)
+
+	sep := string(filepath.Separator)
+	parts := strings.Split(filepath.Clean(dir), sep)
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != PlaceholderDate {
+			kept = append(kept, part)
+		}
+	}
+	dir = strings.Join(kept, sep)
+
+	base = strings.NewReplacer(
+		"-"+PlaceholderDate, "", "_"+PlaceholderDate, "", "."+PlaceholderDate, "",
+		PlaceholderDate+"-", "", PlaceholderDate+"_", "", PlaceholderDate+".", "",
+	).Replace(base)
+	base = strings.ReplaceAll(base, PlaceholderDate, "")
+	if dir == "" {
+		return base
+	}
+	return filepath.Join(dir, base)
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// diskQuota 统计一个日志器所有文件输出（当前文件及轮转产生的旧文件）占用的磁盘空间
+// 设置了 DiskQuota 时，超出配额后从最旧的旧文件开始删除，当前正在写入的文件不会被删除
+type diskQuota struct {
+	limit int64 // 0 表示不限制，只统计
+
+	mu      sync.Mutex // 保护 writers，并保证同一时间只有一次清理
+	writers []*fileWriter
+
+	// millMu 设置了配额时串行执行各写入器的压缩和配额清理（轮转回调不在其中执行），
+	// 避免清理删除其它写入器正在压缩的文件
+	millMu sync.Mutex
+
+	used atomic.Int64 // 最近一次扫描的用量加上之后写入的字节数
+	next atomic.Int64 // 用量超过该值时触发清理
+}
+
+func newDiskQuota(limit int64) *diskQuota {
+	q := &diskQuota{limit: limit}
+	q.next.Store(limit)
+	return q
+}
+
+// register 登记一个文件写入器
+func (q *diskQuota) register(w *fileWriter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.writers = append(q.writers, w)
+	w.quota = q
+}
+
+// add 记录写入的字节数，返回是否需要清理
+func (q *diskQuota) add(n int) bool {
+	used := q.used.Add(int64(n))
+	return q.limit > 0 && used > q.next.Load()
+}
+
+// usage 重新扫描所有文件并返回占用的字节数
+func (q *diskQuota) usage() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	total, _ := q.scan()
+	q.used.Store(total)
+	return total
+}
+
+// enforce 用量超过配额时从最旧的旧文件开始删除
+func (q *diskQuota) enforce() error {
+	if q.limit <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	total, files := q.scan()
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].timestamp.Equal(files[j].timestamp) {
+			return files[i].timestamp.Before(files[j].timestamp)
+		}
+		return files[i].seq < files[j].seq
+	})
+
+	var err error
+	for _, f := range files {
+		if total <= q.limit {
+			break
+		}
+		if removeErr := os.Remove(f.path); removeErr != nil {
+			// 文件已不存在（例如被外部删除）时用量以下次扫描为准，不从 total 中扣除
+			if !os.IsNotExist(removeErr) {
+				err = removeErr
+			}
+			continue
+		}
+		total -= f.size
+	}
+
+	q.used.Store(total)
+	// 只剩当前文件时仍可能超出配额，之后每写入配额的 5% 再检查一次，避免每次写入都扫描目录
+	next := q.limit
+	if total > next {
+		next = total + q.limit/20
+	}
+	q.next.Store(next)
+
+	if err != nil {
+		return fmt.Errorf("enforce disk quota: %w", err)
+	}
+	return nil
+}
+
+// scan 返回所有文件的总大小和可以删除的旧文件，调用方需持有 q.mu
+// 轮转回调尚未执行完的文件计入总大小，但不能删除
+func (q *diskQuota) scan() (int64, []logFileInfo) {
+	var total int64
+	var old []logFileInfo
+	seen := make(map[string]bool)
+	pending := make(map[string]bool)
+	for _, w := range q.writers {
+		w.mu.Lock()
+		current, filename := w.current, w.filename
+		w.mu.Unlock()
+
+		if current != "" {
+			if info, err := os.Stat(current); err == nil {
+				total += info.Size()
+			}
+		}
+		files, err := w.oldLogFiles(current, filename)
+		if err != nil {
+			continue
+		}
+		// 列出文件之后再读取等待回调的轮转，列出文件期间发生的轮转也不会被漏掉
+		w.mu.Lock()
+		for _, r := range w.rotations {
+			pending[r.oldPath] = true
+		}
+		for path := range w.hooking {
+			pending[path] = true
+		}
+		w.mu.Unlock()
+		for _, f := range files {
+			if seen[f.path] {
+				continue
+			}
+			seen[f.path] = true
+			total += f.size
+			if name, _ := trimCompressionSuffix(f.path); !pending[name] {
+				old = append(old, f)
+			}
+		}
+	}
+	return total, old
+}
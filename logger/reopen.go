@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reopen 重新打开该日志器的所有日志文件，用于外部程序（如 logrotate）移走文件之后
+// 切换文件期间的写入会等待，日志不会丢失；关闭后调用无效果
+func (l *Logger) Reopen() error {
+	if l == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, w := range l.closers {
+		if err := w.reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reopen 关闭当前文件并按原路径重新打开
+func (w *fileWriter) reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.file == nil {
+		// 尚未打开的文件在下一次写入时打开
+		return nil
+	}
+
+	closeErr := w.file.Close()
+	w.file = nil
+	w.expandFilename(currentTime())
+	if err := w.openFile(w.activeName()); err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close log file: %w", closeErr)
+	}
+	return nil
+}
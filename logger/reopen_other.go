@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+// watchReopenSignal 非 Unix 系统没有 SIGHUP，只能由程序调用 Reopen
+func watchReopenSignal(l *Logger) (stop func()) {
+	return func() {}
+}
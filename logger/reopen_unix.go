@@ -0,0 +1,35 @@
+//go:build unix
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchReopenSignal 收到 SIGHUP 时重新打开日志文件，返回停止监听的函数
+func watchReopenSignal(l *Logger) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := l.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Failed to reopen log files: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// logFileInfo 轮转产生的旧日志文件
+type logFileInfo struct {
+	path       string
+	timestamp  time.Time // 文件名中的时间，按时间轮转时为周期的结束时间
+	seq        int
+	size       int64
+	compressed bool
+}
+
+// triggerMill 通知后台协程压缩和清理旧文件，调用方需持有 w.mu
+// external 模式下旧文件由 logrotate 等外部工具管理，不清理、不压缩、不执行磁盘配额和轮转回调
+func (w *fileWriter) triggerMill() {
+	if w.rotation == RotateExternal {
+		return
+	}
+	if w.maxBackups == 0 && w.maxAge == 0 && w.compression == CompressionNone && (w.quota == nil || w.quota.limit == 0) && len(w.rotations) == 0 {
+		return
+	}
+	if w.millCh == nil {
+		w.millCh = make(chan struct{}, 1)
+		w.millDone = make(chan struct{})
+		if len(w.onRotate) > 0 {
+			w.hookCh = make(chan rotation, maxQueuedRotateHooks)
+			w.hookDone = make(chan struct{})
+			w.hooking = make(map[string]bool)
+			go w.hookRun()
+		}
+		go w.millRun()
+	}
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+// maxQueuedRotateHooks 每个写入器最多排队等待回调的轮转数，回调执行太慢时之后的轮转不再回调
+const maxQueuedRotateHooks = 64
+
+// millRun 后台协程，写入器关闭后退出
+func (w *fileWriter) millRun() {
+	defer close(w.millDone)
+	for range w.millCh {
+		w.mill()
+	}
+	if w.hookCh != nil {
+		close(w.hookCh)
+	}
+}
+
+// mill 压缩和清理旧文件，把轮转交给回调协程，最后按磁盘配额清理
+// 回调在单独的协程中执行，不持有配额的 millMu，执行慢的回调不会拖住其它写入器的压缩和清理
+// 配额清理不会删除回调尚未执行完的文件，回调收到的旧文件一定存在（除非超出 MaxBackups/MaxAge）
+func (w *fileWriter) mill() {
+	if w.quota != nil && w.quota.limit > 0 {
+		w.quota.millMu.Lock()
+		defer w.quota.millMu.Unlock()
+	}
+
+	// 只处理本轮压缩之前发生的轮转，之后的轮转留给下一轮
+	w.mu.Lock()
+	rotations := w.rotations
+	w.rotations = nil
+	for _, r := range rotations {
+		w.hooking[r.oldPath] = true
+	}
+	w.mu.Unlock()
+
+	if err := w.millRunOnce(); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Failed to clean up old log files for %s: %v\n", w.pattern, err)
+	}
+	w.queueRotateHooks(rotations)
+	if w.quota != nil {
+		if err := w.quota.enforce(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: %v\n", err)
+		}
+	}
+}
+
+// rotation 一次轮转，等待执行回调
+type rotation struct {
+	oldPath string
+	newPath string
+}
+
+// queueRotateHooks 在压缩之后把轮转交给回调协程，队列已满时放弃回调并警告
+func (w *fileWriter) queueRotateHooks(rotations []rotation) {
+	for _, r := range rotations {
+		select {
+		case w.hookCh <- r:
+		default:
+			w.mu.Lock()
+			delete(w.hooking, r.oldPath)
+			w.mu.Unlock()
+			fmt.Fprintf(os.Stderr, "WARN: Too many rotate callbacks pending for %s, skipping callback for %s\n", w.pattern, r.oldPath)
+		}
+	}
+}
+
+// hookRun 回调协程，依次执行轮转回调，压缩协程退出后执行完剩余的回调再退出
+func (w *fileWriter) hookRun() {
+	defer close(w.hookDone)
+	for r := range w.hookCh {
+		w.runRotateHooks(r)
+		w.mu.Lock()
+		delete(w.hooking, r.oldPath)
+		w.mu.Unlock()
+	}
+}
+
+// runRotateHooks 执行一次轮转的回调，旧文件已被压缩时传入压缩后的路径
+func (w *fileWriter) runRotateHooks(r rotation) {
+	oldPath := r.oldPath
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		if compressed := oldPath + compressionSuffixes[w.compression]; compressed != oldPath {
+			if _, err := os.Stat(compressed); err == nil {
+				oldPath = compressed
+			}
+		}
+	}
+	for _, fn := range w.onRotate {
+		if err := callRotateFunc(fn, oldPath, r.newPath); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: Rotate callback failed for %s: %v\n", oldPath, err)
+		}
+	}
+}
+
+// callRotateFunc 执行回调，把 panic 转为错误
+func callRotateFunc(fn RotateFunc, oldPath, newPath string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(oldPath, newPath)
+}
+
+// millRunOnce 按 MaxBackups 和 MaxAge 删除旧文件，并压缩剩余未压缩的文件
+func (w *fileWriter) millRunOnce() error {
+	w.mu.Lock()
+	current, filename := w.current, w.filename
+	w.mu.Unlock()
+
+	files, err := w.oldLogFiles(current, filename)
+	if err != nil {
+		return err
+	}
+
+	var remove, keep []logFileInfo
+	if w.maxBackups > 0 && len(files) > w.maxBackups {
+		remove = append(remove, files[w.maxBackups:]...)
+		files = files[:w.maxBackups]
+	}
+	if w.maxAge > 0 {
+		cutoff := currentTime().Add(-w.maxAge)
+		for _, f := range files {
+			if f.timestamp.Before(cutoff) {
+				remove = append(remove, f)
+			} else {
+				keep = append(keep, f)
+			}
+		}
+	} else {
+		keep = files
+	}
+
+	var errs []error
+	for _, f := range remove {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+		// 按 {date} 分目录时，删除已清空的旧日期目录（非空时 Remove 会失败）
+		if dir := filepath.Dir(f.path); w.hasDate && dir != filepath.Dir(filename) {
+			os.Remove(dir)
+		}
+	}
+	if w.compression != CompressionNone {
+		for _, f := range keep {
+			if f.compressed {
+				continue
+			}
+			if err := compressLogFile(f.path, w.compression, w.compressionLevel); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// oldLogFiles 列出与当前写入器同名的旧日志文件，按时间从新到旧排序
+// 只有文件名中的时间能按当前轮转方式解析的文件才会被管理，避免误删其它日志文件
+func (w *fileWriter) oldLogFiles(current, filename string) ([]logFileInfo, error) {
+	dirs := []string{filepath.Dir(filename)}
+	if dirPattern := filepath.Dir(w.pattern); strings.Contains(dirPattern, PlaceholderDate) {
+		// 目录中包含 {date} 时，旧文件分布在各个日期目录下
+		matches, err := filepath.Glob(strings.ReplaceAll(dirPattern, PlaceholderDate, "*"))
+		if err != nil {
+			return nil, err
+		}
+		dirs = matches
+	}
+
+	var files []logFileInfo
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if path == current {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			f, ok := w.parseLogFile(entry.Name())
+			name, compressed := trimCompressionSuffix(entry.Name())
+			if !ok && dir != filepath.Dir(filename) && name == filepath.Base(filename) {
+				// 之前日期目录中未轮转的日志文件，按修改时间计算
+				f = logFileInfo{timestamp: info.ModTime(), compressed: compressed}
+				ok = true
+			}
+			if !ok {
+				continue
+			}
+			f.path = path
+			f.size = info.Size()
+			files = append(files, f)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].timestamp.Equal(files[j].timestamp) {
+			return files[i].timestamp.After(files[j].timestamp)
+		}
+		return files[i].seq > files[j].seq
+	})
+	return files, nil
+}
+
+// parseLogFile 从文件名中解析时间和序号
+func (w *fileWriter) parseLogFile(name string) (logFileInfo, bool) {
+	var f logFileInfo
+	name, f.compressed = trimCompressionSuffix(name)
+	match := w.oldFileRe.FindStringSubmatch(name)
+	if match == nil {
+		return f, false
+	}
+	stamp := match[1]
+
+	if !w.byTime() {
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			return f, false
+		}
+		f.timestamp = t
+		return f, true
+	}
+
+	if i := strings.LastIndexByte(stamp, '.'); i >= 0 {
+		seq, err := strconv.Atoi(stamp[i+1:])
+		if err != nil || seq <= 0 {
+			return f, false
+		}
+		f.seq = seq
+		stamp = stamp[:i]
+	}
+	start, err := time.ParseInLocation(w.periodLayout(), stamp, w.location)
+	if err != nil {
+		return f, false
+	}
+	if w.interval == RotateHourly {
+		f.timestamp = start.Add(time.Hour)
+	} else {
+		f.timestamp = start.AddDate(0, 0, 1)
+	}
+	return f, true
+}
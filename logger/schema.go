@@ -0,0 +1,478 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// ecsVersion 输出的 ECS 版本
+const ecsVersion = "8.11.0"
+
+// schemaTraceKeys 各 Schema 中链路追踪字段的键名，键为默认的 trace_id、span_id、trace_flags
+var schemaTraceKeys = map[Schema]map[string]string{
+	ECS: {
+		DefaultTraceIDKey: "trace.id",
+		DefaultSpanIDKey:  "span.id",
+	},
+	GCP: {
+		DefaultTraceIDKey: "logging.googleapis.com/trace",
+		DefaultSpanIDKey:  "logging.googleapis.com/spanId",
+	},
+	OTel: {
+		DefaultTraceIDKey:    "TraceId",
+		DefaultSpanIDKey:     "SpanId",
+		DefaultTraceFlagsKey: "TraceFlags",
+	},
+}
+
+// usesSchema 该编码格式的输出是否按 Schema 改写，目前只改写 JSON 输出
+func (c *Config) usesSchema(encoding Encoding) bool {
+	switch c.Schema {
+	case ECS, GCP, OTel:
+		return encoding == JSONEncoding
+	default:
+		return false
+	}
+}
+
+// newSchemaEncoder 基于默认的编码器配置创建按 Schema 输出的 JSON 编码器
+// 先应用 Schema 的键名和样式，再应用用户的 Encoder 配置，因此显式设置的键名优先
+func newSchemaEncoder(base zapcore.EncoderConfig, config *Config) zapcore.Encoder {
+	cfg := base
+	switch config.Schema {
+	case ECS:
+		cfg.TimeKey = "@timestamp"
+		cfg.LevelKey = "log.level"
+		cfg.MessageKey = "message"
+		cfg.NameKey = "log.logger"
+		cfg.StacktraceKey = "error.stack_trace"
+		cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case GCP:
+		cfg.TimeKey = "time"
+		cfg.LevelKey = "severity"
+		cfg.MessageKey = "message"
+		cfg.StacktraceKey = "stack_trace"
+		cfg.EncodeLevel = gcpLevelEncoder
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	case OTel:
+		cfg.TimeKey = "Timestamp"
+		cfg.LevelKey = "SeverityText"
+		cfg.MessageKey = "Body"
+		cfg.StacktraceKey = "exception.stacktrace"
+		cfg.EncodeTime = zapcore.EpochNanosTimeEncoder
+	}
+	// 无效的设置已在创建普通编码器时报告过
+	_ = config.Encoder.apply(&cfg)
+
+	switch config.Schema {
+	case ECS, GCP:
+		return newSchemaJSONEncoder(cfg, config)
+	case OTel:
+		return newOTelEncoder(cfg, config)
+	default:
+		return zapcore.NewJSONEncoder(cfg)
+	}
+}
+
+// schemaJSONEncoder 按 ECS 或 GCP 输出的 JSON 编码器，调用者按 Schema 拆分为对应的字段
+type schemaJSONEncoder struct {
+	zapcore.Encoder
+	schema   Schema
+	origin   bool // 是否输出调用者
+	fullPath bool // 调用者使用完整路径
+}
+
+func newSchemaJSONEncoder(cfg zapcore.EncoderConfig, config *Config) zapcore.Encoder {
+	enc := &schemaJSONEncoder{
+		schema:   config.Schema,
+		origin:   cfg.CallerKey != zapcore.OmitKey,
+		fullPath: config.Encoder.CallerStyle == CallerFull,
+	}
+	cfg.CallerKey = zapcore.OmitKey
+	cfg.FunctionKey = zapcore.OmitKey
+
+	enc.Encoder = zapcore.NewJSONEncoder(cfg)
+	if config.Schema == ECS {
+		enc.AddString("ecs.version", ecsVersion)
+		enc.AddString("service.name", config.appName())
+		enc.AddString("host.hostname", hostname())
+	}
+	return enc
+}
+
+// Clone 实现 zapcore.Encoder
+func (enc *schemaJSONEncoder) Clone() zapcore.Encoder {
+	return &schemaJSONEncoder{
+		Encoder:  enc.Encoder.Clone(),
+		schema:   enc.schema,
+		origin:   enc.origin,
+		fullPath: enc.fullPath,
+	}
+}
+
+// EncodeEntry 实现 zapcore.Encoder
+func (enc *schemaJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if enc.origin && ent.Caller.Defined {
+		file := ent.Caller.File
+		if !enc.fullPath {
+			file = trimCallerPath(file)
+		}
+
+		origin := make([]zapcore.Field, 0, len(fields)+3)
+		switch enc.schema {
+		case ECS:
+			origin = append(origin,
+				zap.String("log.origin.file.name", file),
+				zap.Int("log.origin.file.line", ent.Caller.Line),
+			)
+			if ent.Caller.Function != "" {
+				origin = append(origin, zap.String("log.origin.function", ent.Caller.Function))
+			}
+		case GCP:
+			// LogEntrySourceLocation 中 line 为 int64，按 JSON 映射规则输出为字符串
+			origin = append(origin, zap.Dict("logging.googleapis.com/sourceLocation",
+				zap.String("file", file),
+				zap.String("line", strconv.Itoa(ent.Caller.Line)),
+				zap.String("function", ent.Caller.Function),
+			))
+		}
+		fields = append(origin, fields...)
+	}
+	return enc.Encoder.EncodeEntry(ent, fields)
+}
+
+// gcpLevelEncoder 输出 Cloud Logging 的 severity
+func gcpLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.PanicLevel:
+		enc.AppendString("ALERT")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
+// otelEncoder 按 OpenTelemetry 日志数据模型输出的 JSON 编码器
+// 时间、级别和消息为 Timestamp、SeverityText、SeverityNumber、Body，字段（包括 With 添加的字段）放在 Attributes 中
+type otelEncoder struct {
+	zapcore.Encoder                 // Attributes 部分
+	head            zapcore.Encoder // 顶层字段部分
+	resource        zapcore.Field
+	origin          bool   // 是否输出 code.* 属性
+	fullPath        bool   // code.filepath 使用完整路径
+	stacktraceKey   string // 堆栈属性的键名
+	lineEnding      string
+}
+
+func newOTelEncoder(cfg zapcore.EncoderConfig, config *Config) zapcore.Encoder {
+	enc := &otelEncoder{
+		resource: zap.Dict("Resource",
+			zap.String("service.name", config.appName()),
+			zap.String("host.name", hostname()),
+		),
+		origin:        cfg.CallerKey != zapcore.OmitKey,
+		fullPath:      config.Encoder.CallerStyle == CallerFull,
+		stacktraceKey: cfg.StacktraceKey,
+		lineEnding:    cfg.LineEnding,
+	}
+	if enc.lineEnding == "" {
+		enc.lineEnding = zapcore.DefaultLineEnding
+	}
+
+	headConfig := cfg
+	headConfig.NameKey = zapcore.OmitKey
+	headConfig.CallerKey = zapcore.OmitKey
+	headConfig.FunctionKey = zapcore.OmitKey
+	headConfig.StacktraceKey = zapcore.OmitKey
+	headConfig.LineEnding = "\n"
+	enc.head = zapcore.NewJSONEncoder(headConfig)
+
+	// 属性部分只输出字段
+	attrsConfig := cfg
+	attrsConfig.TimeKey = zapcore.OmitKey
+	attrsConfig.LevelKey = zapcore.OmitKey
+	attrsConfig.NameKey = zapcore.OmitKey
+	attrsConfig.CallerKey = zapcore.OmitKey
+	attrsConfig.FunctionKey = zapcore.OmitKey
+	attrsConfig.MessageKey = zapcore.OmitKey
+	attrsConfig.StacktraceKey = zapcore.OmitKey
+	attrsConfig.LineEnding = "\n"
+	enc.Encoder = zapcore.NewJSONEncoder(attrsConfig)
+	return enc
+}
+
+// Clone 实现 zapcore.Encoder
+func (enc *otelEncoder) Clone() zapcore.Encoder {
+	clone := *enc
+	clone.Encoder = enc.Encoder.Clone()
+	return &clone
+}
+
+// EncodeEntry 实现 zapcore.Encoder
+func (enc *otelEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	top := make([]zapcore.Field, 0, 6)
+	top = append(top, zap.Int("SeverityNumber", otelSeverityNumber(ent.Level)))
+
+	attrs := make([]zapcore.Field, 0, len(fields)+4)
+	if enc.origin && ent.Caller.Defined {
+		file := ent.Caller.File
+		if !enc.fullPath {
+			file = trimCallerPath(file)
+		}
+		attrs = append(attrs,
+			zap.String("code.filepath", file),
+			zap.Int("code.lineno", ent.Caller.Line),
+		)
+		if ent.Caller.Function != "" {
+			attrs = append(attrs, zap.String("code.function", ent.Caller.Function))
+		}
+	}
+	traceKeys := schemaTraceKeys[OTel]
+	for _, f := range fields {
+		switch f.Key {
+		case traceKeys[DefaultTraceIDKey], traceKeys[DefaultSpanIDKey], traceKeys[DefaultTraceFlagsKey]:
+			// 链路追踪字段是数据模型的顶层字段
+			top = append(top, f)
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+	if ent.Stack != "" && enc.stacktraceKey != zapcore.OmitKey {
+		attrs = append(attrs, zap.String(enc.stacktraceKey, ent.Stack))
+	}
+	top = append(top, enc.resource)
+	if ent.LoggerName != "" {
+		top = append(top, zap.Dict("InstrumentationScope", zap.String("Name", ent.LoggerName)))
+	}
+
+	attrsLine, err := enc.Encoder.EncodeEntry(zapcore.Entry{}, attrs)
+	if err != nil {
+		return nil, err
+	}
+	defer attrsLine.Free()
+
+	ent.Stack = ""
+	headLine, err := enc.head.EncodeEntry(ent, top)
+	if err != nil {
+		return nil, err
+	}
+	defer headLine.Free()
+
+	out := logfmtPool.Get()
+	out.Write(bytes.TrimSuffix(headLine.Bytes(), []byte("}\n")))
+	out.AppendString(`,"Attributes":`)
+	out.Write(bytes.TrimSuffix(attrsLine.Bytes(), []byte("\n")))
+	out.AppendByte('}')
+	out.AppendString(enc.lineEnding)
+	return out, nil
+}
+
+// otelSeverityNumber 级别对应的 OpenTelemetry SeverityNumber
+func otelSeverityNumber(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 21
+	case zapcore.PanicLevel:
+		return 22
+	case zapcore.FatalLevel:
+		return 23
+	default:
+		return 0
+	}
+}
+
+// trimCallerPath 保留文件路径的最后一级目录和文件名，与 ShortCallerEncoder 一致
+func trimCallerPath(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx == -1 {
+		return file
+	}
+	idx = strings.LastIndexByte(file[:idx], '/')
+	if idx == -1 {
+		return file
+	}
+	return file[idx+1:]
+}
+
+// schemaCore 按 Schema 改写字段的 zapcore.Core
+// zap.Error 等错误字段在编码器中只能得到错误信息，因此在进入 core 时改写为 Schema 的错误字段，
+// 同时把默认键名的链路追踪字段改写为 Schema 的键名
+type schemaCore struct {
+	zapcore.Core
+	schema Schema
+	errors []zapcore.Field // With 添加的错误字段，写入时才改写，同名的调用处错误字段优先
+}
+
+// wrapSchemaCore 为使用该编码格式的 core 加上字段改写，不需要改写时原样返回
+func wrapSchemaCore(core zapcore.Core, encoding Encoding, config *Config) zapcore.Core {
+	if !config.usesSchema(encoding) {
+		return core
+	}
+	return &schemaCore{Core: core, schema: config.Schema}
+}
+
+// With 实现 zapcore.Core
+// 错误字段不交给底层 core，否则 With 和调用处各有一个 error 时会输出两组相同的 exception.* 键
+func (c *schemaCore) With(fields []zapcore.Field) zapcore.Core {
+	errs := c.errors
+	rest := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if c.errorPrefix(f) != "" {
+			// 写入时才读取错误，与异步写入一样先拍快照
+			errs = withErrorField(errs, freezeField(f))
+			continue
+		}
+		rest = append(rest, f)
+	}
+	return &schemaCore{
+		Core:   c.Core.With(c.rewrite(rest)),
+		schema: c.schema,
+		errors: errs,
+	}
+}
+
+// withErrorField 返回加入 f 后的错误字段，替换同名的字段，不修改 errs
+func withErrorField(errs []zapcore.Field, f zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(errs)+1)
+	for _, e := range errs {
+		if e.Key != f.Key {
+			out = append(out, e)
+		}
+	}
+	return append(out, f)
+}
+
+// Check 实现 zapcore.Core
+func (c *schemaCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write 实现 zapcore.Core
+// With 添加的错误字段放在调用处字段之后，调用处有同名的错误字段时只保留调用处的
+func (c *schemaCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if len(c.errors) > 0 {
+		all := make([]zapcore.Field, 0, len(fields)+len(c.errors))
+		all = append(all, fields...)
+		for _, e := range c.errors {
+			if !hasErrorField(fields, e.Key) {
+				all = append(all, e)
+			}
+		}
+		fields = all
+	}
+	return c.Core.Write(entry, c.rewrite(fields))
+}
+
+// hasErrorField fields 中是否有键名为 key 的错误字段
+func hasErrorField(fields []zapcore.Field, key string) bool {
+	for _, f := range fields {
+		if f.Type == zapcore.ErrorType && f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite 改写错误字段和链路追踪字段，不需要改写时原样返回
+func (c *schemaCore) rewrite(fields []zapcore.Field) []zapcore.Field {
+	traceKeys := schemaTraceKeys[c.schema]
+	changed := false
+	for _, f := range fields {
+		if _, ok := traceKeys[f.Key]; ok || c.errorPrefix(f) != "" {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return fields
+	}
+
+	out := make([]zapcore.Field, 0, len(fields)+1)
+	for _, f := range fields {
+		if prefix := c.errorPrefix(f); prefix != "" {
+			err := f.Interface.(error)
+			out = append(out,
+				zap.String(prefix+".message", errorMessage(err)),
+				zap.String(prefix+".type", errorTypeName(err)),
+			)
+			continue
+		}
+		if key, ok := traceKeys[f.Key]; ok {
+			f.Key = key
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// errorPrefix 错误字段改写后的键名前缀，不改写时返回空字符串
+// ECS 改写为 <key>.message 和 <key>.type；OTel 只把键名为 error 的字段改写为 exception.message 和 exception.type，
+// 其它键名与 ECS 相同；GCP 保持原样
+func (c *schemaCore) errorPrefix(f zapcore.Field) string {
+	if f.Type != zapcore.ErrorType {
+		return ""
+	}
+	if _, ok := f.Interface.(error); !ok {
+		return ""
+	}
+	switch c.schema {
+	case ECS:
+		return f.Key
+	case OTel:
+		if f.Key == "error" {
+			return "exception"
+		}
+		return f.Key
+	default:
+		return ""
+	}
+}
+
+// errorTypeName 返回错误的类型名，异步写入的错误快照返回原错误的类型名
+func errorTypeName(err error) string {
+	switch e := err.(type) {
+	case *frozenError:
+		return e.typeName
+	case *frozenErrorGroup:
+		return e.typeName
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// errorMessage 返回错误信息，Error 方法 panic 时（例如值为 nil 的指针）与 zap 一样输出占位信息
+func errorMessage(err error) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprintf("<PANIC=%v>", r)
+		}
+	}()
+	return err.Error()
+}
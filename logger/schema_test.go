@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// schemaEncoderConfig 与 newLogger 中 Schema 编码器使用的默认配置一致
+func schemaEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	cfg.EncodeDuration = zapcore.StringDurationEncoder
+	cfg.TimeKey = "time"
+	cfg.LevelKey = "level"
+	cfg.NameKey = "logger"
+	cfg.CallerKey = "caller"
+	cfg.FunctionKey = zapcore.OmitKey
+	cfg.MessageKey = "msg"
+	cfg.StacktraceKey = "stacktrace"
+	return cfg
+}
+
+var schemaTestEntry = zapcore.Entry{
+	Level:      zapcore.ErrorLevel,
+	Time:       time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC),
+	LoggerName: "api",
+	Message:    "request failed",
+	Caller:     zapcore.EntryCaller{Defined: true, File: "/src/app/handler/user.go", Line: 42, Function: "app/handler.Get"},
+}
+
+// encodeSchema 经过 schemaCore 的字段改写后按 Schema 编码一条日志，with 为 With 添加的字段
+func encodeSchema(t *testing.T, schema Schema, with []zapcore.Field, fields ...zapcore.Field) string {
+	t.Helper()
+	config := &Config{Schema: schema, AppName: "shop"}
+	var buf bytes.Buffer
+	core := wrapSchemaCore(
+		zapcore.NewCore(newSchemaEncoder(schemaEncoderConfig(), config), zapcore.AddSync(&buf), zapcore.DebugLevel),
+		JSONEncoding, config,
+	)
+	if len(with) > 0 {
+		core = core.With(with)
+	}
+	if err := core.Write(schemaTestEntry, fields); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestSchemaOutput(t *testing.T) {
+	host := hostname()
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: fs.ErrNotExist}
+	tests := []struct {
+		name   string
+		schema Schema
+		with   []zapcore.Field
+		fields []zapcore.Field
+		want   string
+	}{
+		{
+			name:   "ecs",
+			schema: ECS,
+			with:   []zapcore.Field{zap.String("user", "alice")},
+			fields: []zapcore.Field{
+				zap.Error(pathErr),
+				zap.NamedError("cause", errors.New("boom")),
+				zap.String(DefaultTraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+				zap.String(DefaultSpanIDKey, "00f067aa0ba902b7"),
+			},
+			want: `{"log.level":"error","@timestamp":"2026-10-16T08:30:00.000Z","log.logger":"api","message":"request failed",` +
+				`"ecs.version":"8.11.0","service.name":"shop","host.hostname":"` + host + `","user":"alice",` +
+				`"log.origin.file.name":"handler/user.go","log.origin.file.line":42,"log.origin.function":"app/handler.Get",` +
+				`"error.message":"open /etc/app.yaml: file does not exist","error.type":"*fs.PathError",` +
+				`"cause.message":"boom","cause.type":"*errors.errorString",` +
+				`"trace.id":"4bf92f3577b34da6a3ce929d0e0e4736","span.id":"00f067aa0ba902b7"}`,
+		},
+		{
+			// 异步写入的错误快照保留原来的类型名
+			name:   "ecs frozen error",
+			schema: ECS,
+			fields: []zapcore.Field{zap.Error(&frozenError{msg: "boom", typeName: "*fs.PathError"})},
+			want: `{"log.level":"error","@timestamp":"2026-10-16T08:30:00.000Z","log.logger":"api","message":"request failed",` +
+				`"ecs.version":"8.11.0","service.name":"shop","host.hostname":"` + host + `",` +
+				`"log.origin.file.name":"handler/user.go","log.origin.file.line":42,"log.origin.function":"app/handler.Get",` +
+				`"error.message":"boom","error.type":"*fs.PathError"}`,
+		},
+		{
+			name:   "gcp",
+			schema: GCP,
+			with:   []zapcore.Field{zap.String("user", "alice")},
+			fields: []zapcore.Field{
+				zap.Error(errors.New("boom")),
+				zap.String(DefaultTraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+				zap.String(DefaultSpanIDKey, "00f067aa0ba902b7"),
+			},
+			// sourceLocation 的 line 按 LogEntrySourceLocation 的 JSON 映射输出为字符串，错误保持 zap 的格式
+			want: `{"severity":"ERROR","time":"2026-10-16T08:30:00Z","logger":"api","message":"request failed","user":"alice",` +
+				`"logging.googleapis.com/sourceLocation":{"file":"handler/user.go","line":"42","function":"app/handler.Get"},` +
+				`"error":"boom",` +
+				`"logging.googleapis.com/trace":"4bf92f3577b34da6a3ce929d0e0e4736","logging.googleapis.com/spanId":"00f067aa0ba902b7"}`,
+		},
+		{
+			name:   "otel",
+			schema: OTel,
+			with:   []zapcore.Field{zap.String("user", "alice"), zap.Error(errors.New("setup"))},
+			fields: []zapcore.Field{
+				zap.Int("attempt", 2),
+				zap.Error(pathErr),
+				zap.String(DefaultTraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+				zap.String(DefaultSpanIDKey, "00f067aa0ba902b7"),
+				zap.String(DefaultTraceFlagsKey, "01"),
+			},
+			// 链路追踪字段移到顶层，其余字段（包括 With 添加的字段）放在 Attributes 中；
+			// With 和调用处都有 error 时只输出调用处的 exception.*
+			want: `{"SeverityText":"ERROR","Timestamp":1792139400000000000,"Body":"request failed","SeverityNumber":17,` +
+				`"TraceId":"4bf92f3577b34da6a3ce929d0e0e4736","SpanId":"00f067aa0ba902b7","TraceFlags":"01",` +
+				`"Resource":{"service.name":"shop","host.name":"` + host + `"},"InstrumentationScope":{"Name":"api"},` +
+				`"Attributes":{"user":"alice",` +
+				`"code.filepath":"handler/user.go","code.lineno":42,"code.function":"app/handler.Get","attempt":2,` +
+				`"exception.message":"open /etc/app.yaml: file does not exist","exception.type":"*fs.PathError"}}`,
+		},
+		{
+			// 调用处没有 error 时使用 With 添加的 error，其它键名的错误改写为 <key>.message 和 <key>.type
+			name:   "otel with error",
+			schema: OTel,
+			with:   []zapcore.Field{zap.Error(errors.New("setup"))},
+			fields: []zapcore.Field{zap.NamedError("cause", pathErr)},
+			want: `{"SeverityText":"ERROR","Timestamp":1792139400000000000,"Body":"request failed","SeverityNumber":17,` +
+				`"Resource":{"service.name":"shop","host.name":"` + host + `"},"InstrumentationScope":{"Name":"api"},` +
+				`"Attributes":{"code.filepath":"handler/user.go","code.lineno":42,"code.function":"app/handler.Get",` +
+				`"cause.message":"open /etc/app.yaml: file does not exist","cause.type":"*fs.PathError",` +
+				`"exception.message":"setup","exception.type":"*errors.errorString"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeSchema(t, tt.schema, tt.with, tt.fields...); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// spillBuffer 异步队列已满时的临时磁盘缓冲（OverflowSpill）
+// 日志按顺序追加到临时文件，写入协程写完队列后按顺序回放，回放完的文件会被删除
+type spillBuffer struct {
+	mu     sync.Mutex
+	file   *os.File
+	cores  []zapcore.Core // 与文件中的每一行一一对应，回放时写入对应的 core
+	notify chan struct{}
+}
+
+// spillRecord 溢出到磁盘的一条日志
+type spillRecord struct {
+	Level      zapcore.Level `json:"level"`
+	Time       time.Time     `json:"time"`
+	LoggerName string        `json:"logger,omitempty"`
+	Message    string        `json:"msg"`
+	Caller     *spillCaller  `json:"caller,omitempty"`
+	Stack      string        `json:"stack,omitempty"`
+	Fields     []spillField  `json:"fields,omitempty"`
+}
+
+type spillCaller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+func newSpillBuffer() *spillBuffer {
+	return &spillBuffer{notify: make(chan struct{}, 1)}
+}
+
+// pushIfActive 已有日志溢出时追加到磁盘并返回 true 及写磁盘的错误
+// 写入失败时该日志无法回放，由调用方计为丢弃；仍然返回 true 以保证后续日志不会越过它
+func (s *spillBuffer) pushIfActive(item asyncEntry) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cores) == 0 {
+		return false, nil
+	}
+	return true, s.appendLocked(item)
+}
+
+// push 把日志追加到磁盘
+func (s *spillBuffer) push(item asyncEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.appendLocked(item)
+}
+
+func (s *spillBuffer) appendLocked(item asyncEntry) error {
+	line, err := encodeSpillRecord(item)
+	if err != nil {
+		return err
+	}
+	if s.file == nil {
+		if s.file, err = os.CreateTemp("", "zapw-spill-*.jsonl"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	s.cores = append(s.cores, item.core)
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// drain 取出当前所有溢出的日志并按顺序回放，返回回放成功和无法回放的数量
+// 回放期间新溢出的日志写入新的临时文件，留给下一次 drain
+func (s *spillBuffer) drain(write func(asyncEntry)) (written, lost int) {
+	s.mu.Lock()
+	file, cores := s.file, s.cores
+	s.file, s.cores = nil, nil
+	s.mu.Unlock()
+
+	if file == nil {
+		return 0, 0
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return 0, len(cores)
+	}
+	reader := bufio.NewReader(file)
+	for i, core := range cores {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return written, len(cores) - i
+		}
+		item, err := decodeSpillRecord(line)
+		if err != nil {
+			lost++
+			continue
+		}
+		item.core = core
+		write(item)
+		written++
+	}
+	return written, lost
+}
+
+// encodeSpillRecord 把日志编码为一行 JSON，字段按类型原样保存，回放时还原为相同类型的字段
+func encodeSpillRecord(item asyncEntry) ([]byte, error) {
+	record := spillRecord{
+		Level:      item.entry.Level,
+		Time:       item.entry.Time,
+		LoggerName: item.entry.LoggerName,
+		Message:    item.entry.Message,
+		Stack:      item.entry.Stack,
+	}
+	if item.entry.Caller.Defined {
+		record.Caller = &spillCaller{
+			File:     item.entry.Caller.File,
+			Line:     item.entry.Caller.Line,
+			Function: item.entry.Caller.Function,
+		}
+	}
+	for _, field := range item.fields {
+		record.Fields = append(record.Fields, newSpillField(field))
+	}
+
+	// 反射值按原样保存，不能被转义 HTML 字符，否则回放后输出不同
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(record); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSpillRecord 按原顺序还原日志和字段
+func decodeSpillRecord(line []byte) (asyncEntry, error) {
+	var record spillRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return asyncEntry{}, err
+	}
+
+	item := asyncEntry{
+		entry: zapcore.Entry{
+			Level:      record.Level,
+			Time:       record.Time,
+			LoggerName: record.LoggerName,
+			Message:    record.Message,
+			Stack:      record.Stack,
+		},
+	}
+	if record.Caller != nil {
+		item.entry.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			File:     record.Caller.File,
+			Line:     record.Caller.Line,
+			Function: record.Caller.Function,
+		}
+	}
+	if len(record.Fields) > 0 {
+		item.fields = make([]zapcore.Field, 0, len(record.Fields))
+		for _, f := range record.Fields {
+			item.fields = append(item.fields, f.field())
+		}
+	}
+	return item, nil
+}
+
+// spillField 溢出到磁盘的一个字段，保存的是 freezeFields 生成的快照
+// 数值类字段保存 zap 原始的 Integer（浮点数为 IEEE 754 位模式，因此 NaN 和 Inf 也能还原）；
+// 对象和数组保存记录下的字段和元素，回放时按原来的结构交给编码器，与未溢出的日志输出相同
+type spillField struct {
+	Key       string            `json:"k,omitempty"`
+	Type      zapcore.FieldType `json:"t"`
+	Integer   int64             `json:"i,omitempty"`
+	String    string            `json:"s,omitempty"`
+	Bytes     []byte            `json:"b,omitempty"`
+	Value     json.RawMessage   `json:"v,omitempty"`  // 反射值编码后的 JSON
+	Fields    []spillField      `json:"f,omitempty"`  // 对象的字段、数组的元素或多错误的原因
+	Err       *string           `json:"x,omitempty"`  // MarshalLogObject/MarshalLogArray 返回的错误
+	ErrorType string            `json:"e,omitempty"`  // 错误的原始类型名，ECS/OTel 的 error.type 使用
+	Verbose   string            `json:"ev,omitempty"` // 错误的详细信息
+	Group     bool              `json:"g,omitempty"`  // 是否为多错误
+}
+
+// newSpillField 把字段的快照转换为可以写入磁盘的形式
+func newSpillField(f zapcore.Field) spillField {
+	f = freezeField(f)
+	sf := spillField{Key: f.Key, Type: f.Type}
+	switch f.Type {
+	case zapcore.BoolType, zapcore.DurationType,
+		zapcore.Float64Type, zapcore.Float32Type,
+		zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		sf.Integer = f.Integer
+	case zapcore.TimeType:
+		sf.Integer = f.Integer
+		if loc, ok := f.Interface.(*time.Location); ok {
+			sf.String = loc.String()
+		}
+	case zapcore.TimeFullType:
+		t, _ := f.Interface.(time.Time)
+		sf.String = t.Format(time.RFC3339Nano)
+	case zapcore.StringType, zapcore.NamespaceType, zapcore.SkipType:
+		sf.String = f.String
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		sf.Bytes, _ = f.Interface.([]byte)
+	case zapcore.Complex128Type:
+		c, _ := f.Interface.(complex128)
+		sf.String = strconv.FormatComplex(c, 'g', -1, 128)
+	case zapcore.Complex64Type:
+		c, _ := f.Interface.(complex64)
+		sf.String = strconv.FormatComplex(complex128(c), 'g', -1, 64)
+	case zapcore.ReflectType:
+		sf.Value, _ = f.Interface.(json.RawMessage)
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		o := f.Interface.(*frozenObject)
+		for _, field := range o.fields {
+			sf.Fields = append(sf.Fields, newSpillField(field))
+		}
+		sf.Err = spillError(o.err)
+	case zapcore.ArrayMarshalerType:
+		a := f.Interface.(*frozenArray)
+		for _, elem := range a.elems {
+			sf.Fields = append(sf.Fields, newSpillField(elem))
+		}
+		sf.Err = spillError(a.err)
+	case zapcore.ErrorType:
+		var e *frozenError
+		switch v := f.Interface.(type) {
+		case *frozenError:
+			e = v
+		case *frozenErrorGroup:
+			e = &v.frozenError
+			sf.Group = true
+			for _, cause := range v.causes {
+				sf.Fields = append(sf.Fields, newSpillField(zapcore.Field{Type: zapcore.ErrorType, Interface: cause}))
+			}
+		}
+		sf.String, sf.ErrorType, sf.Verbose = e.msg, e.typeName, e.verbose
+	}
+	return sf
+}
+
+func spillError(err error) *string {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	return &msg
+}
+
+// field 还原为 zap 字段
+func (sf spillField) field() zapcore.Field {
+	f := zapcore.Field{Key: sf.Key, Type: sf.Type, Integer: sf.Integer, String: sf.String}
+	switch sf.Type {
+	case zapcore.TimeType:
+		f.String = ""
+		if sf.String != "" {
+			if loc, err := time.LoadLocation(sf.String); err == nil {
+				f.Interface = loc
+			}
+		}
+	case zapcore.TimeFullType:
+		t, _ := time.Parse(time.RFC3339Nano, sf.String)
+		f.String = ""
+		f.Interface = t
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		f.Interface = sf.Bytes
+	case zapcore.Complex128Type:
+		c, _ := strconv.ParseComplex(sf.String, 128)
+		f.String = ""
+		f.Interface = c
+	case zapcore.Complex64Type:
+		c, _ := strconv.ParseComplex(sf.String, 64)
+		f.String = ""
+		f.Interface = complex64(c)
+	case zapcore.ReflectType:
+		f.Interface = sf.Value
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		o := &frozenObject{err: sf.err()}
+		for _, field := range sf.Fields {
+			o.fields = append(o.fields, field.field())
+		}
+		f.Interface = o
+	case zapcore.ArrayMarshalerType:
+		a := &frozenArray{err: sf.err()}
+		for _, elem := range sf.Fields {
+			a.elems = append(a.elems, elem.field())
+		}
+		f.Interface = a
+	case zapcore.ErrorType:
+		f.String = ""
+		e := frozenError{msg: sf.String, verbose: sf.Verbose, typeName: sf.ErrorType}
+		if !sf.Group {
+			f.Interface = &e
+			break
+		}
+		group := &frozenErrorGroup{frozenError: e}
+		for _, cause := range sf.Fields {
+			group.causes = append(group.causes, cause.field().Interface.(error))
+		}
+		f.Interface = group
+	}
+	return f
+}
+
+func (sf spillField) err() error {
+	if sf.Err == nil {
+		return nil
+	}
+	return errors.New(*sf.Err)
+}
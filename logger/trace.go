@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// TraceConfig 带 context 的日志方法从 OpenTelemetry span 中提取链路追踪字段的配置
+// 键名为空时使用 trace_id、span_id、trace_flags，设置为 "-" 表示不输出该项
+type TraceConfig struct {
+	Disabled      bool        `json:"disabled" yaml:"disabled"` // 关闭自动添加链路追踪字段
+	TraceIDKey    string      `json:"trace_id_key" yaml:"trace_id_key"`
+	SpanIDKey     string      `json:"span_id_key" yaml:"span_id_key"`
+	TraceFlagsKey string      `json:"trace_flags_key" yaml:"trace_flags_key"`
+	Format        TraceFormat `json:"format" yaml:"format"` // trace_id 和 span_id 的格式：hex（默认）或 decimal
+	// trace_id 的前缀，例如 Cloud Logging 需要的 "projects/my-project/traces/"
+	TraceIDPrefix string `json:"trace_id_prefix" yaml:"trace_id_prefix"`
+}
+
+// fields 返回 ctx 中有效且被采样的 span 的链路追踪字段，没有这样的 span 或已关闭时返回 nil
+// 未采样的 span 不会上报到链路追踪系统，输出它的 trace_id 也无法跳转
+func (t TraceConfig) fields(ctx context.Context) []zap.Field {
+	if t.Disabled || ctx == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, 3)
+	if key := traceKey(t.TraceIDKey, DefaultTraceIDKey); key != "" {
+		fields = append(fields, zap.String(key, t.TraceIDPrefix+t.formatTraceID(sc.TraceID())))
+	}
+	if key := traceKey(t.SpanIDKey, DefaultSpanIDKey); key != "" {
+		fields = append(fields, zap.String(key, t.formatSpanID(sc.SpanID())))
+	}
+	if key := traceKey(t.TraceFlagsKey, DefaultTraceFlagsKey); key != "" {
+		fields = append(fields, zap.String(key, sc.TraceFlags().String()))
+	}
+	return fields
+}
+
+// traceKey 为空时返回默认键名，"-" 表示不输出
+func traceKey(key, defaultKey string) string {
+	switch key {
+	case "":
+		return defaultKey
+	case omitKey:
+		return ""
+	default:
+		return key
+	}
+}
+
+// formatTraceID decimal 格式取低 64 位的十进制（与 Datadog 一致）
+func (t TraceConfig) formatTraceID(id trace.TraceID) string {
+	if t.Format == TraceFormatDecimal {
+		return strconv.FormatUint(binary.BigEndian.Uint64(id[8:]), 10)
+	}
+	return id.String()
+}
+
+func (t TraceConfig) formatSpanID(id trace.SpanID) string {
+	if t.Format == TraceFormatDecimal {
+		return strconv.FormatUint(binary.BigEndian.Uint64(id[:]), 10)
+	}
+	return id.String()
+}
+
+// validate 检查链路追踪配置
+func (t TraceConfig) validate() error {
+	switch t.Format {
+	case "", TraceFormatHex, TraceFormatDecimal:
+		return nil
+	default:
+		return fmt.Errorf("trace.format: unknown format %q", t.Format)
+	}
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// spanContext 返回带有 span 的 context，flags 为 trace.FlagsSampled 时表示已采样
+func spanContext(flags trace.TraceFlags) context.Context {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: flags,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+// newObservedLogger 创建输出到 observer 的日志器
+func newObservedLogger(config *Config) (*Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return &Logger{zapLogger: zap.New(core), config: config}, logs
+}
+
+func TestTraceFields(t *testing.T) {
+	sampled := spanContext(trace.FlagsSampled)
+	tests := []struct {
+		name  string
+		trace TraceConfig
+		ctx   context.Context
+		want  map[string]interface{}
+	}{
+		{
+			name: "hex",
+			ctx:  sampled,
+			want: map[string]interface{}{
+				"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":     "00f067aa0ba902b7",
+				"trace_flags": "01",
+			},
+		},
+		{
+			// decimal 取 trace_id 的低 64 位
+			name:  "decimal",
+			trace: TraceConfig{Format: TraceFormatDecimal},
+			ctx:   sampled,
+			want: map[string]interface{}{
+				"trace_id":    "11803532876627986230",
+				"span_id":     "67667974448284343",
+				"trace_flags": "01",
+			},
+		},
+		{
+			name:  "prefix",
+			trace: TraceConfig{TraceIDPrefix: "projects/shop/traces/"},
+			ctx:   sampled,
+			want: map[string]interface{}{
+				"trace_id":    "projects/shop/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":     "00f067aa0ba902b7",
+				"trace_flags": "01",
+			},
+		},
+		{
+			name:  "renamed and omitted keys",
+			trace: TraceConfig{TraceIDKey: "dd.trace_id", SpanIDKey: "dd.span_id", TraceFlagsKey: omitKey},
+			ctx:   sampled,
+			want: map[string]interface{}{
+				"dd.trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"dd.span_id":  "00f067aa0ba902b7",
+			},
+		},
+		{
+			name:  "disabled",
+			trace: TraceConfig{Disabled: true},
+			ctx:   sampled,
+			want:  map[string]interface{}{},
+		},
+		{
+			name: "no span",
+			ctx:  context.Background(),
+			want: map[string]interface{}{},
+		},
+		{
+			name: "invalid span",
+			ctx:  trace.ContextWithSpanContext(context.Background(), trace.SpanContext{}),
+			want: map[string]interface{}{},
+		},
+		{
+			name: "unsampled span",
+			ctx:  spanContext(0),
+			want: map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, logs := newObservedLogger(&Config{Trace: tt.trace})
+			l.InfoCtx(tt.ctx, "m")
+			if got := logs.All()[0].ContextMap(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 子日志器的所有 Ctx 方法都添加链路追踪字段，并保留 With 添加的字段
+func TestTraceFieldsOnChildLogger(t *testing.T) {
+	parent, logs := newObservedLogger(&Config{Trace: TraceConfig{SpanIDKey: omitKey}})
+	child := parent.With(zap.String("user", "alice"))
+	ctx := spanContext(trace.FlagsSampled)
+
+	methods := map[string]func(context.Context, string, ...zap.Field){
+		"DebugCtx": child.DebugCtx,
+		"InfoCtx":  child.InfoCtx,
+		"WarnCtx":  child.WarnCtx,
+		"ErrorCtx": child.ErrorCtx,
+	}
+	want := map[string]interface{}{
+		"user":        "alice",
+		"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+		"trace_flags": "01",
+		"attempt":     int64(2),
+	}
+	for name, log := range methods {
+		log(ctx, name, zap.Int("attempt", 2))
+	}
+	if got := logs.Len(); got != len(methods) {
+		t.Fatalf("logged %d entries, want %d", got, len(methods))
+	}
+	for _, entry := range logs.All() {
+		if got := entry.ContextMap(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s fields = %v, want %v", entry.Message, got, want)
+		}
+	}
+}
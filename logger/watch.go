@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReloadFunc 配置热加载回调，err 为 nil 表示新配置已生效，否则之前的配置保持不变
+// 新配置生效后关闭旧日志器失败（例如旧的异步队列未能按时写完）不影响新配置，不会传给回调，只在标准错误输出警告
+type ReloadFunc func(config *Config, err error)
+
+// configWatcher 轮询配置文件修改时间并重建全局日志器
+type configWatcher struct {
+	path     string
+	interval time.Duration
+	onReload ReloadFunc
+	options  []Option
+
+	current *Logger   // 由 watcher 创建、当前生效的日志器
+	modTime time.Time // 上次加载时文件的修改时间
+	size    int64     // 上次加载时文件的大小
+	missing bool      // 文件暂时不可访问，避免重复回调
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// WatchConfig 从配置文件初始化全局日志器，并按 interval 轮询文件的修改时间，
+// 文件变化后使用新配置（级别、路径、轮转、编码等）重建全局日志器，旧日志器的文件会被同步并关闭
+//
+// options 在每次加载时都会覆盖文件中的配置；onReload 可以为 nil；
+// 加载或 Config.Validate 校验失败时回调会收到错误，之前的配置保持生效；返回的 stop 用于停止监听
+func WatchConfig(path string, interval time.Duration, onReload ReloadFunc, options ...Option) (stop func(), err error) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("watch config file: %w", err)
+	}
+	// watcher 管理的始终是全局日志器
+	options = append(options[:len(options):len(options)], WithSetGlobal(true))
+	logger, err := NewFromFile(path, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &configWatcher{
+		path:     path,
+		interval: interval,
+		onReload: onReload,
+		options:  options,
+		current:  logger,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(w.stop)
+			<-w.done
+		})
+	}, nil
+}
+
+func (w *configWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 检查文件是否变化，变化时重新加载
+func (w *configWatcher) check() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		// 编辑器保存文件时可能先删除再创建，只在第一次失败时通知
+		if !w.missing {
+			w.missing = true
+			w.notify(nil, fmt.Errorf("watch config file: %w", err))
+		}
+		return
+	}
+	w.missing = false
+
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return
+	}
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+	w.reload()
+}
+
+// reload 使用新配置创建日志器并替换全局日志器，失败时保留之前的日志器
+func (w *configWatcher) reload() {
+	fileConfig, err := LoadConfig(w.path)
+	if err != nil {
+		w.notify(nil, err)
+		return
+	}
+
+	config, err := buildConfig(append([]Option{WithConfig(fileConfig)}, w.options...)...)
+	if err != nil {
+		w.notify(fileConfig, err)
+		return
+	}
+	// 无论是否启用严格模式，热加载都先完整校验，避免把正在运行的日志器替换成降级的配置
+	if err := config.Validate(); err != nil {
+		w.notify(config, err)
+		return
+	}
+
+	logger, err := newLogger(config)
+	if err != nil {
+		w.notify(config, err)
+		return
+	}
+
+	// 新日志器已经设置为全局日志器，旧日志器写完异步队列中的日志后关闭文件
+	// 等待异步队列、压缩和轮转回调都以一个检查周期为限，执行慢的回调不会卡住之后的重新加载
+	previous := w.current
+	w.current = logger
+	ctx, cancel := context.WithTimeout(context.Background(), w.interval)
+	defer cancel()
+	if err := previous.Close(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Failed to close previous log files after reloading %s: %v\n", w.path, err)
+	}
+	w.notify(logger.config, nil)
+}
+
+func (w *configWatcher) notify(config *Config, err error) {
+	if w.onReload != nil {
+		w.onReload(config, err)
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// redirectStd 把标准输出换成管道（与容器、systemd 下相同，fsync 会失败），标准错误写到临时文件，返回读取标准错误内容的函数
+func redirectStd(t *testing.T) (stderr func() string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go io.Copy(io.Discard, r)
+	errFile, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderrFile := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, errFile
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderrFile
+		w.Close()
+		errFile.Close()
+	})
+	return func() string {
+		data, err := os.ReadFile(errFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestWatchConfigCleanReloadPrintsNoWarning(t *testing.T) {
+	defer ReplaceGlobal(nil)()
+	stderr := redirectStd(t)
+
+	path := filepath.Join(t.TempDir(), "logger.yaml")
+	if err := os.WriteFile(path, []byte("level: info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan error, 1)
+	stop, err := WatchConfig(path, 10*time.Millisecond, func(config *Config, err error) {
+		reloaded <- err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	Info("before reload")
+
+	if err := os.WriteFile(path, []byte("level: debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+	stop()
+
+	if got := GetLevel(); got != DebugLevel {
+		t.Errorf("level after reload = %q, want %q", got, DebugLevel)
+	}
+	if got := stderr(); got != "" {
+		t.Errorf("stderr after a clean reload = %q, want empty", got)
+	}
+}
@@ -0,0 +1,422 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	megabyte = 1024 * 1024
+
+	// 按大小轮转时备份文件名中的时间格式，与 lumberjack 保持一致
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// currentTime 轮转和清理使用的当前时间，测试时替换
+var currentTime = time.Now
+
+// fileWriter 日志文件写入器，支持按大小和/或按时间轮转，轮转后在后台压缩和清理旧文件
+// 关闭后的写入转到标准错误输出，避免重新打开已关闭的文件
+type fileWriter struct {
+	mu sync.Mutex
+
+	pattern          string         // 配置的日志文件路径，可能包含 {date}
+	filename         string         // pattern 展开后的日志文件路径
+	hasDate          bool           // pattern 中包含 {date}
+	dateEnd          time.Time      // filename 中日期的结束时间，之后需要重新展开
+	oldFileRe        *regexp.Regexp // 匹配轮转产生的旧文件名，子匹配为时间部分
+	link             string         // 指向当前文件的符号链接，为空表示不维护
+	quota            *diskQuota     // 所属日志器的磁盘配额，由 diskQuota.register 设置
+	onRotate         []RotateFunc
+	rotations        []rotation // 等待执行回调的轮转
+	linkWarned       bool
+	rotation         RotationMode
+	interval         RotationInterval
+	location         *time.Location
+	maxSize          int64
+	maxBackups       int
+	maxAge           time.Duration
+	compression      Compression
+	compressionLevel int
+
+	file        *os.File
+	current     string // 当前写入的文件路径
+	size        int64
+	periodStart time.Time // 按时间轮转时当前周期的开始时间
+	periodEnd   time.Time
+	seq         int // size+time 模式下同一周期内的序号，0 表示不带序号
+	closed      bool
+
+	millCh   chan struct{} // 通知后台协程压缩和清理旧文件
+	millDone chan struct{}
+
+	hookCh   chan rotation // 等待回调协程执行的轮转，队列有上限，回调不会阻塞压缩和配额清理
+	hookDone chan struct{}
+	hooking  map[string]bool // 已交给回调协程、回调尚未执行完的旧文件
+}
+
+// newFileWriter 根据配置创建文件写入器，文件在第一次写入时打开
+func newFileWriter(filename string, config *Config) (*fileWriter, error) {
+	location, err := loadLocation(config.RotationTimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := int64(config.MaxSize) * megabyte
+	if config.MaxSize <= 0 {
+		maxSize = DefaultMaxSize * megabyte
+	}
+	rotation := config.Rotation
+	if rotation == "" {
+		rotation = DefaultRotation
+	}
+	interval := config.RotationInterval
+	if interval == "" {
+		interval = DefaultRotationInterval
+	}
+
+	var link string
+	if config.Symlink {
+		link = stripDate(filename)
+	}
+
+	return &fileWriter{
+		pattern:          filename,
+		filename:         filename,
+		hasDate:          strings.Contains(filename, PlaceholderDate),
+		oldFileRe:        oldFileRegexp(filename),
+		rotation:         rotation,
+		interval:         interval,
+		location:         location,
+		maxSize:          maxSize,
+		maxBackups:       config.MaxBackups,
+		maxAge:           time.Duration(config.MaxAge) * 24 * time.Hour,
+		compression:      config.resolveCompression(),
+		compressionLevel: config.CompressionLevel,
+		link:             link,
+		onRotate:         config.onRotate,
+	}, nil
+}
+
+// loadLocation 解析轮转使用的时区，空字符串和 "Local" 表示本地时区
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("rotation_time_zone: %w", err)
+	}
+	return location, nil
+}
+
+// Write 实现 io.Writer
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.Stderr.Write(p)
+	}
+
+	now := currentTime()
+	if w.file == nil {
+		if err := w.openExistingOrNew(now, len(p)); err != nil {
+			return 0, err
+		}
+	} else if w.shouldRotate(now, len(p)) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if w.quota != nil && w.quota.add(n) {
+		w.triggerMill()
+	}
+	return n, err
+}
+
+// Sync 实现 zapcore.WriteSyncer
+func (w *fileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭日志文件，并在 ctx 截止前等待后台的压缩、清理和轮转回调完成
+// 超时返回 ctx 的错误，后台任务继续执行完
+func (w *fileWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	millDone, hookDone := w.millDone, w.hookDone
+	if w.millCh != nil {
+		close(w.millCh)
+	}
+	w.mu.Unlock()
+
+	// 压缩协程退出时关闭回调队列，回调协程执行完队列中的回调后退出
+	for _, done := range []chan struct{}{millDone, hookDone} {
+		if done == nil {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return errors.Join(err, fmt.Errorf("wait for %s cleanup: %w", w.pattern, ctx.Err()))
+		}
+	}
+	return err
+}
+
+func (w *fileWriter) bySize() bool {
+	return w.rotation == RotateBySize || w.rotation == RotateBySizeAndTime
+}
+
+func (w *fileWriter) byTime() bool {
+	return w.rotation == RotateByTime || w.rotation == RotateBySizeAndTime
+}
+
+// shouldRotate 判断写入 n 字节前是否需要轮转
+func (w *fileWriter) shouldRotate(now time.Time, n int) bool {
+	if w.byTime() && !now.Before(w.periodEnd) {
+		return true
+	}
+	if w.dateChanged(now) {
+		return true
+	}
+	return w.bySize() && w.size > 0 && w.size+int64(n) > w.maxSize
+}
+
+// dateChanged 文件名中的 {date} 在 now 时是否已经变化
+func (w *fileWriter) dateChanged(now time.Time) bool {
+	return w.hasDate && !now.Before(w.dateEnd)
+}
+
+// openExistingOrNew 打开当前应写入的文件，已存在时追加写入，写满时先轮转
+func (w *fileWriter) openExistingOrNew(now time.Time, n int) error {
+	w.expandFilename(now)
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	// 启动时清理一次旧文件
+	w.triggerMill()
+
+	if w.byTime() {
+		w.setPeriod(now)
+		w.seq = 0
+		if w.bySize() {
+			// 重启后继续写入本周期最后一个文件
+			for {
+				if _, err := os.Stat(w.periodName(w.seq + 1)); err != nil {
+					break
+				}
+				w.seq++
+			}
+		}
+	}
+
+	name := w.activeName()
+	info, err := os.Stat(name)
+	if err == nil && w.bySize() && info.Size() > 0 && info.Size()+int64(n) > w.maxSize {
+		return w.rotate(now)
+	}
+	return w.openFile(name)
+}
+
+// rotate 关闭当前文件并切换到新文件
+func (w *fileWriter) rotate(now time.Time) error {
+	old := w.current
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+
+	if w.byTime() {
+		w.expandFilename(now)
+		if !now.Before(w.periodEnd) {
+			w.setPeriod(now)
+			w.seq = 0
+		} else {
+			// 同一周期内超过大小限制
+			w.seq++
+		}
+	} else if w.bySize() && !w.dateChanged(now) {
+		// 按大小轮转：当前文件重命名为带时间戳的备份，再创建同名新文件
+		backup := w.backupName(now)
+		if err := os.Rename(w.filename, backup); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+		old = backup
+		w.expandFilename(now)
+	} else {
+		// {date} 变化时前一天的文件原样保留，切换到新日期的路径（external 模式只会走到这里）
+		w.expandFilename(now)
+	}
+
+	if err := w.openFile(w.activeName()); err != nil {
+		return err
+	}
+	if old != "" && len(w.onRotate) > 0 && w.rotation != RotateExternal {
+		w.rotations = append(w.rotations, rotation{oldPath: old, newPath: w.current})
+	}
+	w.triggerMill()
+	return nil
+}
+
+func (w *fileWriter) openFile(name string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	w.file = file
+	w.current = name
+	w.size = info.Size()
+	w.updateLink()
+	return nil
+}
+
+// updateLink 把符号链接原子地指向当前文件：先创建临时链接再重命名覆盖
+// 链接路径上已有普通文件时不覆盖，避免误删日志
+func (w *fileWriter) updateLink() {
+	if w.link == "" || w.link == w.current {
+		return
+	}
+
+	err := func() error {
+		if info, err := os.Lstat(w.link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("%s exists and is not a symlink", w.link)
+		}
+		target := w.current
+		if rel, err := filepath.Rel(filepath.Dir(w.link), w.current); err == nil {
+			target = rel
+		}
+		tmp := filepath.Join(filepath.Dir(w.link), "."+filepath.Base(w.link)+".tmp-"+strconv.Itoa(os.Getpid()))
+		os.Remove(tmp)
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, w.link); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return nil
+	}()
+	if err != nil && !w.linkWarned {
+		// 只提示一次，避免每次轮转都输出
+		w.linkWarned = true
+		fmt.Fprintf(os.Stderr, "WARN: Failed to update log symlink: %v\n", err)
+	}
+}
+
+// expandFilename 按 now 所在的日期展开文件名中的 {date}
+func (w *fileWriter) expandFilename(now time.Time) {
+	if !w.hasDate {
+		return
+	}
+	t := now.In(w.location)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, w.location)
+	w.filename = expandDate(w.pattern, start)
+	w.dateEnd = start.AddDate(0, 0, 1)
+}
+
+// dir 日志文件在 now 时所在的目录
+func (w *fileWriter) dir(now time.Time) string {
+	return filepath.Dir(expandDate(w.pattern, now.In(w.location)))
+}
+
+// oldFileRegexp 根据文件名模板生成匹配旧文件的正则，例如 info.log 匹配 info-<时间>.log
+func oldFileRegexp(pattern string) *regexp.Regexp {
+	base := filepath.Base(pattern)
+	ext := filepath.Ext(base)
+	parts := strings.Split(strings.TrimSuffix(base, ext), PlaceholderDate)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	stem := strings.Join(parts, `\d{4}-\d{2}-\d{2}`)
+	return regexp.MustCompile("^" + stem + "-(.+)" + regexp.QuoteMeta(ext) + "$")
+}
+
+// setPeriod 计算 now 所在的轮转周期
+func (w *fileWriter) setPeriod(now time.Time) {
+	t := now.In(w.location)
+	if w.interval == RotateHourly {
+		w.periodStart = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, w.location)
+		w.periodEnd = w.periodStart.Add(time.Hour)
+		return
+	}
+	w.periodStart = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, w.location)
+	w.periodEnd = w.periodStart.AddDate(0, 0, 1)
+}
+
+// activeName 当前应写入的文件路径
+func (w *fileWriter) activeName() string {
+	if w.byTime() {
+		return w.periodName(w.seq)
+	}
+	return w.filename
+}
+
+// periodName 按时间轮转的文件名，例如 info-2026-10-16.log、info-2026-10-16.1.log
+func (w *fileWriter) periodName(seq int) string {
+	prefix, ext := w.prefixAndExt()
+	name := prefix + w.periodStart.Format(w.periodLayout())
+	if seq > 0 {
+		name += "." + strconv.Itoa(seq)
+	}
+	return filepath.Join(filepath.Dir(w.filename), name+ext)
+}
+
+// backupName 按大小轮转的备份文件名，例如 app-2026-10-16T08-30-00.000.log
+func (w *fileWriter) backupName(now time.Time) string {
+	prefix, ext := w.prefixAndExt()
+	return filepath.Join(filepath.Dir(w.filename), prefix+now.UTC().Format(backupTimeFormat)+ext)
+}
+
+func (w *fileWriter) periodLayout() string {
+	if w.interval == RotateHourly {
+		return "2006-01-02T15"
+	}
+	return "2006-01-02"
+}
+
+// prefixAndExt 把 app.log 拆分为 "app-" 和 ".log"
+func (w *fileWriter) prefixAndExt() (prefix, ext string) {
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
@@ -0,0 +1,633 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 替换 currentTime，让轮转发生在确定的时间
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func useFakeClock(t *testing.T, now time.Time) *fakeClock {
+	t.Helper()
+	clock := &fakeClock{now: now}
+	previous := currentTime
+	currentTime = clock.Now
+	t.Cleanup(func() { currentTime = previous })
+	return clock
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestWriter 创建写入器，maxSize 以字节为单位，便于用少量数据触发按大小轮转
+func newTestWriter(t *testing.T, pattern string, config *Config, maxSize int64) *fileWriter {
+	t.Helper()
+	if config.RotationTimeZone == "" {
+		config.RotationTimeZone = "UTC"
+	}
+	w, err := newFileWriter(pattern, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.maxSize = maxSize
+	t.Cleanup(func() { w.Close(context.Background()) })
+	return w
+}
+
+func writeLine(t *testing.T, w *fileWriter, n int) {
+	t.Helper()
+	if _, err := w.Write([]byte(strings.Repeat("x", n-1) + "\n")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listFiles 返回 dir 下所有文件的相对路径及大小
+func listFiles(t *testing.T, dir string) map[string]int64 {
+	t.Helper()
+	files := make(map[string]int64)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files[filepath.ToSlash(rel)] = info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func checkFiles(t *testing.T, dir string, want map[string]int64) {
+	t.Helper()
+	got := listFiles(t, dir)
+	if len(got) != len(want) {
+		t.Errorf("files = %v, want %v", sortedKeys(got), sortedKeys(want))
+		return
+	}
+	for name, size := range want {
+		if got[name] != size {
+			t.Errorf("files = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateBySize}, 100)
+
+	writeLine(t, w, 60)
+	clock.Add(time.Second)
+	writeLine(t, w, 60) // 超过 100 字节，先轮转
+	writeLine(t, w, 30)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"app.log":                         90,
+		"app-2026-10-15T08-30-01.000.log": 60,
+	})
+}
+
+func TestRotateByTime(t *testing.T) {
+	tests := []struct {
+		interval RotationInterval
+		start    time.Time
+		want     map[string]int64
+	}{
+		{
+			RotateDaily,
+			time.Date(2026, 10, 15, 23, 59, 59, 0, time.UTC),
+			map[string]int64{"app-2026-10-15.log": 10, "app-2026-10-16.log": 10},
+		},
+		{
+			RotateHourly,
+			time.Date(2026, 10, 15, 8, 59, 59, 0, time.UTC),
+			map[string]int64{"app-2026-10-15T08.log": 10, "app-2026-10-15T09.log": 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.interval), func(t *testing.T) {
+			dir := t.TempDir()
+			clock := useFakeClock(t, tt.start)
+			w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateByTime, RotationInterval: tt.interval}, 100)
+
+			writeLine(t, w, 10)
+			clock.Add(time.Second)
+			writeLine(t, w, 10)
+			w.Close(context.Background())
+
+			checkFiles(t, dir, tt.want)
+		})
+	}
+}
+
+func TestRotateByTimeUsesTimeZone(t *testing.T) {
+	dir := t.TempDir()
+	// UTC 16:00 是东八区的第二天 0 点
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 15, 59, 59, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateByTime, RotationTimeZone: "Asia/Shanghai"}, 100)
+	if w.location.String() != "Asia/Shanghai" {
+		t.Skip("time zone database not available")
+	}
+
+	writeLine(t, w, 10)
+	clock.Add(time.Second)
+	writeLine(t, w, 10)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{"app-2026-10-15.log": 10, "app-2026-10-16.log": 10})
+}
+
+func TestRotateBySizeAndTime(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 15, 23, 0, 0, 0, time.UTC)
+	clock := useFakeClock(t, start)
+	config := &Config{Rotation: RotateBySizeAndTime}
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), config, 100)
+
+	writeLine(t, w, 60)
+	writeLine(t, w, 60)
+	writeLine(t, w, 60)
+	w.Close(context.Background())
+
+	// 重启后继续写入本周期最后一个文件
+	w = newTestWriter(t, filepath.Join(dir, "app.log"), config, 100)
+	writeLine(t, w, 10)
+	// 新的周期从不带序号的文件开始
+	clock.Set(start.Add(time.Hour))
+	writeLine(t, w, 10)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"app-2026-10-15.log":   60,
+		"app-2026-10-15.1.log": 60,
+		"app-2026-10-15.2.log": 70,
+		"app-2026-10-16.log":   10,
+	})
+}
+
+func TestRotateDateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 23, 59, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "{date}", "info.log"), &Config{Rotation: RotateBySize}, 100)
+
+	writeLine(t, w, 60)
+	// 日期变化时切换到新目录，前一天的文件保持原名，不当作写满的文件重命名
+	clock.Add(2 * time.Minute)
+	writeLine(t, w, 60)
+	// 同一天内写满时在当天的目录中按大小轮转
+	clock.Add(time.Second)
+	writeLine(t, w, 60)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"2026-10-15/info.log":                         60,
+		"2026-10-16/info-2026-10-16T00-01-01.000.log": 60,
+		"2026-10-16/info.log":                         60,
+	})
+}
+
+func TestRotateDateFilename(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 23, 59, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "info-{date}.log"), &Config{Rotation: RotateBySize}, 100)
+
+	writeLine(t, w, 60)
+	clock.Add(2 * time.Minute)
+	writeLine(t, w, 60)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"info-2026-10-15.log": 60,
+		"info-2026-10-16.log": 60,
+	})
+}
+
+// 每次轮转后符号链接都指向正在写入的文件，链接使用相对路径
+func TestSymlinkFollowsRotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		config  Config
+		link    string
+		targets []string
+	}{
+		{
+			name:    "time",
+			pattern: "app.log",
+			config:  Config{Rotation: RotateByTime, Symlink: true},
+			link:    "app.log",
+			targets: []string{"app-2026-10-15.log", "app-2026-10-16.log"},
+		},
+		{
+			name:    "date directory",
+			pattern: filepath.Join("{date}", "info.log"),
+			config:  Config{Rotation: RotateBySize, Symlink: true},
+			link:    "info.log",
+			targets: []string{filepath.Join("2026-10-15", "info.log"), filepath.Join("2026-10-16", "info.log")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			clock := useFakeClock(t, time.Date(2026, 10, 15, 23, 59, 59, 0, time.UTC))
+			w := newTestWriter(t, filepath.Join(dir, tt.pattern), &tt.config, 100)
+			link := filepath.Join(dir, tt.link)
+
+			for i, target := range tt.targets {
+				if i > 0 {
+					clock.Add(time.Second)
+				}
+				line := strings.Repeat(strconv.Itoa(i), 9) + "\n"
+				if _, err := w.Write([]byte(line)); err != nil {
+					t.Fatal(err)
+				}
+				if got, err := os.Readlink(link); err != nil || got != target {
+					t.Fatalf("after write %d: link -> %q (%v), want %q", i, got, err, target)
+				}
+				if data, err := os.ReadFile(link); err != nil || string(data) != line {
+					t.Errorf("after write %d: content through link = %q (%v), want %q", i, data, err, line)
+				}
+			}
+		})
+	}
+}
+
+// 文件名模板中的 {date} 在日期变化后重新展开，其它占位符保持创建日志器时的值
+func TestPlaceholdersExpandAtRotation(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 23, 59, 59, 0, time.UTC))
+	l, err := New(
+		WithSetGlobal(false),
+		WithConsoleOutput(false),
+		WithBasePath(dir),
+		WithAppName("shop"),
+		WithFilePattern(filepath.Join("{base}", "{app}-{pid}", "{date}", "{level}.log")),
+		WithRotationTimeZone("UTC"),
+		WithCompression(CompressionNone, 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before midnight")
+	clock.Add(time.Second)
+	l.Info("after midnight")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	app := "shop-" + strconv.Itoa(os.Getpid())
+	for _, tt := range []struct {
+		date string
+		msg  string
+	}{
+		{"2026-10-15", "before midnight"},
+		{"2026-10-16", "after midnight"},
+	} {
+		for _, level := range []string{"app", "info"} {
+			path := filepath.Join(dir, app, tt.date, level+".log")
+			if got := readMessages(t, path); len(got) != 1 || got[0] != tt.msg {
+				t.Errorf("%s = %v, want [%s]", path, got, tt.msg)
+			}
+		}
+	}
+	if files := listFiles(t, dir); len(files) != 4 {
+		t.Errorf("files = %v, want app.log and info.log in each date directory", sortedKeys(files))
+	}
+}
+
+func TestRotateMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateBySize, MaxBackups: 2}, 100)
+
+	for i := 0; i < 5; i++ {
+		writeLine(t, w, 60)
+		clock.Add(time.Second)
+	}
+	w.Close(context.Background())
+
+	// 5 次写入产生 4 个备份，只保留最新的 2 个
+	checkFiles(t, dir, map[string]int64{
+		"app.log":                         60,
+		"app-2026-10-15T08-30-03.000.log": 60,
+		"app-2026-10-15T08-30-04.000.log": 60,
+	})
+}
+
+func TestRotateMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateBySize, MaxAge: 1}, 100)
+
+	writeLine(t, w, 60)
+	clock.Add(time.Hour)
+	writeLine(t, w, 60)
+	// 第一个备份在两天后过期，第二个备份还在一天以内
+	clock.Add(47 * time.Hour)
+	writeLine(t, w, 60)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"app.log":                         60,
+		"app-2026-10-17T08-30-00.000.log": 60,
+	})
+}
+
+func TestExternalRotationKeepsOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+	old := filepath.Join(dir, "app-2020-01-01T00-00-00.000.log")
+	if err := os.WriteFile(old, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{
+		Rotation:    RotateExternal,
+		MaxBackups:  1,
+		MaxAge:      1,
+		Compression: CompressionGzip,
+	}, 100)
+
+	// external 模式不按大小轮转，也不清理或压缩 logrotate 管理的文件
+	writeLine(t, w, 60)
+	writeLine(t, w, 60)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"app.log":                         120,
+		"app-2020-01-01T00-00-00.000.log": 4,
+	})
+}
+
+// logrotate 移走文件后，Reopen 之前的日志仍写入被移走的文件，之后写入按原路径新建的文件
+func TestReopenAfterExternalRename(t *testing.T) {
+	dir := t.TempDir()
+	useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+	w := newTestWriter(t, filepath.Join(dir, "app.log"), &Config{Rotation: RotateExternal}, 100)
+	l := &Logger{closers: []*fileWriter{w}}
+
+	writeLine(t, w, 60)
+	if err := os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	writeLine(t, w, 30)
+	if err := l.Reopen(); err != nil {
+		t.Fatalf("Reopen() = %v", err)
+	}
+	writeLine(t, w, 40)
+	w.Close(context.Background())
+
+	checkFiles(t, dir, map[string]int64{
+		"app.log":   40,
+		"app.log.1": 90,
+	})
+}
+
+// 多个写入器共享配额时，配额清理不能删除其它写入器正在压缩的文件，回调收到的旧文件也必须存在
+func TestDiskQuotaWithCompression(t *testing.T) {
+	stderr := redirectStd(t)
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+
+	var mu sync.Mutex
+	var missing []string
+	config := &Config{
+		Rotation:    RotateBySize,
+		Compression: CompressionZstd,
+		onRotate: []RotateFunc{func(oldPath, newPath string) error {
+			if _, err := os.Stat(oldPath); err != nil {
+				mu.Lock()
+				missing = append(missing, oldPath)
+				mu.Unlock()
+			}
+			return nil
+		}},
+	}
+	const limit = 8 << 10
+	quota := newDiskQuota(limit)
+	var writers []*fileWriter
+	for _, name := range []string{"app", "error", "info", "warn"} {
+		w := newTestWriter(t, filepath.Join(dir, name+".log"), config, 4<<10)
+		quota.register(w)
+		writers = append(writers, w)
+	}
+
+	for i := 0; i < 40; i++ {
+		clock.Add(time.Second)
+		for _, w := range writers {
+			writeLine(t, w, 3<<10)
+		}
+	}
+	for _, w := range writers {
+		w.Close(context.Background())
+	}
+
+	if got := stderr(); got != "" {
+		t.Errorf("stderr = %q, want empty", got)
+	}
+	if len(missing) > 0 {
+		t.Errorf("rotate callbacks got missing files: %v", missing)
+	}
+	// 当前文件不会被删除，其余空间不超过配额
+	if got := quota.usage(); got > limit+int64(len(writers))*(3<<10) {
+		t.Errorf("usage = %d, want at most the quota plus the current files", got)
+	}
+}
+
+// 执行很慢的轮转回调不会拖住共享配额的其它写入器的压缩，Close 在 ctx 截止时返回而不是一直等待回调
+func TestSlowRotateCallbackWithCloseDeadline(t *testing.T) {
+	dir := t.TempDir()
+	clock := useFakeClock(t, time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	slowConfig := &Config{
+		Rotation: RotateBySize,
+		onRotate: []RotateFunc{func(oldPath, newPath string) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil
+		}},
+	}
+	quota := newDiskQuota(1 << 20)
+	slow := newTestWriter(t, filepath.Join(dir, "slow.log"), slowConfig, 100)
+	other := newTestWriter(t, filepath.Join(dir, "other.log"), &Config{Rotation: RotateBySize, Compression: CompressionGzip}, 100)
+	quota.register(slow)
+	quota.register(other)
+	defer close(release)
+
+	writeLine(t, slow, 60)
+	clock.Add(time.Second)
+	writeLine(t, slow, 60)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotate callback was not called")
+	}
+
+	// 回调阻塞期间，共享配额的其它写入器照常压缩
+	writeLine(t, other, 60)
+	clock.Add(time.Second)
+	writeLine(t, other, 60)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := other.Close(ctx); err != nil {
+		t.Fatalf("close other writer: %v", err)
+	}
+	if _, ok := listFiles(t, dir)["other-2026-10-15T08-30-02.000.log.gz"]; !ok {
+		t.Errorf("files = %v, want the rotated file of the other writer compressed", sortedKeys(listFiles(t, dir)))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := slow.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Close() took %v, want it to return at the deadline", elapsed)
+	}
+}
+
+// useVerify 替换压缩文件的校验
+func useVerify(t *testing.T, verify func(path string, compression Compression, want []byte) error) {
+	t.Helper()
+	previous := verifyCompressed
+	verifyCompressed = verify
+	t.Cleanup(func() { verifyCompressed = previous })
+}
+
+func writeLogFile(t *testing.T, dir string) (string, []byte) {
+	t.Helper()
+	src := filepath.Join(dir, "app-2026-10-15T08-30-00.000.log")
+	content := []byte(strings.Repeat("{\"level\":\"INFO\",\"msg\":\"hello\"}\n", 100))
+	if err := os.WriteFile(src, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return src, content
+}
+
+func TestCompressVerifiesArchive(t *testing.T) {
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			dir := t.TempDir()
+			src, content := writeLogFile(t, dir)
+			dst := src + compressionSuffixes[compression]
+
+			var verified bool
+			useVerify(t, func(path string, compression Compression, want []byte) error {
+				// 校验时原文件必须还在
+				if _, err := os.Stat(src); err != nil {
+					t.Errorf("original removed before verification: %v", err)
+				}
+				verified = true
+				return verifyCompressedFile(path, compression, want)
+			})
+			if err := compressLogFile(src, compression, 0); err != nil {
+				t.Fatal(err)
+			}
+			if !verified {
+				t.Error("archive was not verified")
+			}
+			if _, err := os.Stat(src); !os.IsNotExist(err) {
+				t.Errorf("original still exists after compression: %v", err)
+			}
+
+			f, err := os.Open(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			sum := sha256.New()
+			if err := decompressTo(sum, f, compression); err != nil {
+				t.Fatal(err)
+			}
+			if want := sha256.Sum256(content); !bytes.Equal(sum.Sum(nil), want[:]) {
+				t.Error("decompressed content differs from the original")
+			}
+		})
+	}
+}
+
+func TestCompressKeepsOriginalWhenVerifyFails(t *testing.T) {
+	dir := t.TempDir()
+	src, content := writeLogFile(t, dir)
+	useVerify(t, func(path string, compression Compression, want []byte) error {
+		// 模拟写入后损坏的压缩文件
+		if err := os.Truncate(path, 10); err != nil {
+			t.Fatal(err)
+		}
+		return verifyCompressedFile(path, compression, want)
+	})
+
+	if err := compressLogFile(src, CompressionZstd, 0); err == nil {
+		t.Fatal("compression succeeded with a corrupt archive")
+	}
+	checkFiles(t, dir, map[string]int64{filepath.Base(src): int64(len(content))})
+}
+
+func TestCompressKeepsArchiveWhenOriginalIsGone(t *testing.T) {
+	dir := t.TempDir()
+	src, _ := writeLogFile(t, dir)
+	useVerify(t, func(path string, compression Compression, want []byte) error {
+		// 校验期间原文件被其它清理删除
+		if err := os.Remove(src); err != nil {
+			t.Fatal(err)
+		}
+		return verifyCompressedFile(path, compression, want)
+	})
+
+	if err := compressLogFile(src, CompressionGzip, 0); err != nil {
+		t.Fatalf("compression failed after the original was removed: %v", err)
+	}
+	if _, err := os.Stat(src + ".gz"); err != nil {
+		t.Errorf("verified archive was removed: %v", err)
+	}
+}